@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMiss 缓存中不存在该键或已过期
+var ErrMiss = errors.New("缓存未命中")
+
+// Cache 键值缓存
+type Cache interface {
+	// Name 返回缓存后端名称，用于日志和健康检查
+	Name() string
+	// Get 读取键的值，不存在或已过期时返回 ErrMiss
+	Get(ctx context.Context, key string) (string, error)
+	// Set 写入键值，ttl 后过期
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	// Delete 删除键，键不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// Ping 检查缓存后端是否可用
+	Ping(ctx context.Context) error
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Noop 不保存任何数据的缓存，每次读取都未命中
+type Noop struct{}
+
+// NewNoop 创建不缓存任何数据的缓存
+func NewNoop() Noop {
+	return Noop{}
+}
+
+// Name 返回缓存后端名称
+func (Noop) Name() string {
+	return "none"
+}
+
+// Get 始终返回 ErrMiss
+func (Noop) Get(ctx context.Context, key string) (string, error) {
+	return "", ErrMiss
+}
+
+// Set 丢弃写入的值
+func (Noop) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return nil
+}
+
+// Delete 无需删除
+func (Noop) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
+// Ping 始终可用
+func (Noop) Ping(ctx context.Context) error {
+	return nil
+}
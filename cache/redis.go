@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Redis 基于Redis的缓存，多个实例共享
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis 创建使用指定Redis客户端的缓存
+func NewRedis(client *redis.Client) *Redis {
+	return &Redis{client: client}
+}
+
+// Name 返回缓存后端名称
+func (r *Redis) Name() string {
+	return "redis"
+}
+
+// Get 读取键的值
+func (r *Redis) Get(ctx context.Context, key string) (string, error) {
+	value, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", ErrMiss
+	}
+	return value, err
+}
+
+// Set 写入键值
+func (r *Redis) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete 删除键
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, key).Err()
+}
+
+// Ping 检查Redis连接
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
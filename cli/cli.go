@@ -0,0 +1,367 @@
+// Package cli 实现 sharesth 命令行客户端：通过 /api/contents 接口分享、列出和删除内容
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"sharesth/utils"
+)
+
+// 各子命令的用法
+const (
+	loginUsage = `用法: sharesth login -server <服务地址> [-token <API令牌>]
+
+验证并保存服务地址和API令牌，未指定 -token 时从标准输入读取`
+
+	shareUsage = `用法: sharesth share [选项] [文件...]
+
+分享文件或标准输入的内容并输出短链接，未指定文件或文件为 "-" 时读取标准输入
+默认根据文件名和内容选择类型：图片、二进制文件、Markdown、代码或纯文本`
+
+	listUsage = `用法: sharesth ls [选项]
+
+列出我的内容`
+
+	removeUsage = `用法: sharesth rm <ID>...
+
+删除我的内容`
+)
+
+// commands 命令行客户端的子命令
+var commands = map[string]func(args []string) error{
+	"login": runLogin,
+	"share": runShare,
+	"ls":    runList,
+	"rm":    runRemove,
+}
+
+// errUsage 参数错误，用法已输出
+var errUsage = errors.New("参数错误")
+
+// IsCommand 返回 name 是否为命令行客户端的子命令
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Run 执行子命令，返回进程退出码
+func Run(name string, args []string) int {
+	err := commands[name](args)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	default:
+		fmt.Fprintf(os.Stderr, "sharesth %s: %v\n", name, err)
+		return 1
+	}
+}
+
+// newFlagSet 创建子命令的参数解析器，出错时输出子命令用法
+func newFlagSet(name string, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet("sharesth "+name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usage)
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags 解析参数，参数错误时返回 errUsage
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// newConfiguredClient 读取配置并创建客户端，未登录时返回错误
+func newConfiguredClient() (*Client, error) {
+	settings, err := LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.Server == "" || settings.Token == "" {
+		return nil, fmt.Errorf("未配置服务地址或API令牌，请先运行 sharesth login")
+	}
+	return NewClient(settings), nil
+}
+
+// runLogin 验证并保存服务地址和API令牌
+func runLogin(args []string) error {
+	settings, err := LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	fs := newFlagSet("login", loginUsage)
+	fs.StringVar(&settings.Server, "server", settings.Server, "服务地址，如 https://share.example.com")
+	token := fs.String("token", "", "API令牌，在网页的令牌管理中创建，需要 read、write 和 delete 授权范围")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || settings.Server == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	settings.Server = strings.TrimRight(settings.Server, "/")
+	if u, err := url.Parse(settings.Server); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("服务地址必须以 http:// 或 https:// 开头: %s", settings.Server)
+	}
+
+	settings.Token = *token
+	if settings.Token == "" {
+		fmt.Fprint(os.Stderr, "API令牌: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("读取API令牌失败: %v", err)
+		}
+		settings.Token = strings.TrimSpace(line)
+	}
+	if settings.Token == "" {
+		return fmt.Errorf("API令牌不能为空")
+	}
+
+	// 列出一条内容以验证服务地址和令牌
+	if _, err := NewClient(settings).List(ListOptions{PerPage: 1}); err != nil {
+		return fmt.Errorf("验证失败: %v", err)
+	}
+
+	path, err := SaveSettings(settings)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已登录 %s，配置保存在 %s\n", settings.Server, path)
+	return nil
+}
+
+// runShare 分享文件或标准输入的内容，每个文件输出一行短链接
+func runShare(args []string) error {
+	var opts ShareOptions
+	fs := newFlagSet("share", shareUsage)
+	fs.StringVar(&opts.Type, "type", "", "内容类型: text、markdown、code、image 或 file，默认自动识别")
+	fs.StringVar(&opts.Title, "title", "", "标题，默认使用文件名")
+	fs.BoolVar(&opts.Public, "public", false, "公开内容")
+	fs.StringVar(&opts.Expire, "expire", "", "有效时长，如 30m、2h、7d 或 never")
+	fs.IntVar(&opts.MaxViews, "max-views", 0, "最大访问次数，1 表示阅后即焚")
+	fs.StringVar(&opts.Language, "lang", "", "代码语言，默认根据文件名和内容识别")
+	fs.StringVar(&opts.Tags, "tags", "", "标签，多个标签用逗号分隔")
+	fs.StringVar(&opts.Password, "password", "", "访问密码")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	switch opts.Type {
+	case "", "text", "markdown", "code", "image", "file":
+	default:
+		return fmt.Errorf("不支持的内容类型: %s", opts.Type)
+	}
+
+	client, err := newConfiguredClient()
+	if err != nil {
+		return err
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	failed := 0
+	for _, file := range files {
+		link, err := shareFile(client, opts, file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "分享 %s 失败: %v\n", file, err)
+			failed++
+			continue
+		}
+		fmt.Println(link)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d 个内容分享失败", failed)
+	}
+	return nil
+}
+
+// shareFile 分享一个文件，"-" 表示标准输入
+func shareFile(client *Client, opts ShareOptions, file string) (string, error) {
+	var body io.Reader = os.Stdin
+	name := ""
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		body = f
+		name = filepath.Base(file)
+	}
+
+	reader := bufio.NewReader(body)
+	head, err := reader.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	contentType, mimeType, language := detectContent(name, head)
+	if opts.Type == "" {
+		opts.Type = contentType
+	}
+	if opts.Type == "code" && opts.Language == "" {
+		opts.Language = language
+	}
+	if opts.Title == "" && name != "" && opts.Type != "image" && opts.Type != "file" {
+		opts.Title = name
+	}
+	if name == "" {
+		name = "stdin"
+	}
+
+	return client.Share(opts, Upload{Name: name, MIMEType: mimeType, Body: reader})
+}
+
+// detectContent 根据文件名和开头的内容推断内容类型，同时返回文件的MIME类型和代码语言
+// 标准输入的文件名为空
+func detectContent(name string, head []byte) (contentType string, mimeType string, language string) {
+	ext := strings.ToLower(filepath.Ext(name))
+	mimeType = mime.TypeByExtension(ext)
+	if mimeType == "" {
+		mimeType = http.DetectContentType(head)
+	}
+
+	switch {
+	// SVG 可能包含脚本，按代码分享
+	case strings.HasPrefix(mimeType, "image/") && !strings.HasPrefix(mimeType, "image/svg"):
+		return "image", mimeType, ""
+	case !looksLikeText(head):
+		return "file", mimeType, ""
+	case ext == ".md" || ext == ".markdown":
+		return "markdown", mimeType, ""
+	case name == "" || ext == ".txt" || ext == ".log":
+		return "text", mimeType, ""
+	}
+
+	if language := utils.DetectLanguage("", name); language != utils.PlainTextLanguage {
+		return "code", mimeType, language
+	}
+	return "text", mimeType, ""
+}
+
+// looksLikeText 判断内容开头是否为UTF-8文本，允许末尾有被截断的字符
+func looksLikeText(head []byte) bool {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return false
+	}
+	for i := 0; i < utf8.UTFMax && i <= len(head); i++ {
+		if utf8.Valid(head[:len(head)-i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// runList 以表格形式列出我的内容
+func runList(args []string) error {
+	var opts ListOptions
+	fs := newFlagSet("ls", listUsage)
+	fs.StringVar(&opts.Query, "query", "", "搜索标题和正文")
+	fs.StringVar(&opts.Type, "type", "", "只列出指定类型的内容")
+	fs.StringVar(&opts.Tag, "tag", "", "只列出带指定标签的内容")
+	fs.IntVar(&opts.Page, "page", 1, "页码")
+	fs.IntVar(&opts.PerPage, "per-page", 20, "每页数量")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	client, err := newConfiguredClient()
+	if err != nil {
+		return err
+	}
+
+	list, err := client.List(opts)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\t类型\t公开\t访问\t创建时间\t过期时间\t标题")
+	for _, item := range list.Items {
+		public := "否"
+		if item.IsPublic {
+			public = "是"
+		}
+		views := fmt.Sprint(item.ViewCount)
+		if item.MaxViews > 0 {
+			views += fmt.Sprintf("/%d", item.MaxViews)
+		}
+		expires := "永不"
+		if item.ExpiresAt != nil {
+			expires = item.ExpiresAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", item.ShortID, item.Type, public, views,
+			item.CreateTime.Local().Format("2006-01-02 15:04"), expires, item.Title)
+	}
+	w.Flush()
+
+	pages := int64(1)
+	if list.PerPage > 0 && list.Total > 0 {
+		pages = (list.Total + int64(list.PerPage) - 1) / int64(list.PerPage)
+	}
+	fmt.Printf("共 %d 条，第 %d/%d 页\n", list.Total, list.Page, pages)
+	return nil
+}
+
+// runRemove 删除我的内容
+func runRemove(args []string) error {
+	fs := newFlagSet("rm", removeUsage)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	client, err := newConfiguredClient()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, shortID := range fs.Args() {
+		if err := client.Delete(shortID); err != nil {
+			fmt.Fprintf(os.Stderr, "删除 %s 失败: %v\n", shortID, err)
+			failed++
+			continue
+		}
+		fmt.Printf("已删除 %s\n", shortID)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d 个内容删除失败", failed)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client 调用分享服务 /api/contents 接口的客户端
+type Client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+// NewClient 创建客户端，请求携带配置中的API令牌
+func NewClient(settings Settings) *Client {
+	return &Client{
+		server: settings.Server,
+		token:  settings.Token,
+		http:   &http.Client{},
+	}
+}
+
+// ShareOptions 创建内容的选项，空值表示使用服务端默认值
+type ShareOptions struct {
+	Type     string // text、markdown、code、image 或 file
+	Title    string
+	Public   bool
+	Expire   string // 有效时长，如 "30m"、"2h"、"7d" 或 "never"
+	MaxViews int
+	Language string
+	Tags     string
+	Password string
+}
+
+// Upload 要分享的内容
+type Upload struct {
+	Name     string    // 文件名，图片和文件类型上传时使用
+	MIMEType string    // 图片和文件的MIME类型，服务端据此校验图片
+	Body     io.Reader // 内容
+}
+
+// ContentItem 内容列表中的一项
+type ContentItem struct {
+	ShortID    string     `json:"short_id"`
+	Type       string     `json:"type"`
+	Title      string     `json:"title"`
+	CreateTime time.Time  `json:"createTime"`
+	IsPublic   bool       `json:"is_public"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	MaxViews   int        `json:"max_views"`
+	ViewCount  int        `json:"view_count"`
+	Tags       []string   `json:"tags"`
+}
+
+// ContentList 分页的内容列表
+type ContentList struct {
+	Items   []ContentItem `json:"items"`
+	Total   int64         `json:"total"`
+	Page    int           `json:"page"`
+	PerPage int           `json:"per_page"`
+}
+
+// ListOptions 列出内容的筛选和分页参数
+type ListOptions struct {
+	Query   string
+	Type    string
+	Tag     string
+	Page    int
+	PerPage int
+}
+
+// Share 创建内容，返回短链接
+// 文本类内容通过 content 字段提交，图片和文件通过 file 字段上传，请求体以流的形式发送
+func (c *Client) Share(opts ShareOptions, upload Upload) (string, error) {
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+
+	go func() {
+		writer.CloseWithError(writeShareForm(form, opts, upload))
+	}()
+
+	req, err := c.newRequest(http.MethodPost, "/api/contents", nil, body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	var result struct {
+		ShortLink string `json:"shortLink"`
+	}
+	if err := c.do(req, &result); err != nil {
+		return "", err
+	}
+
+	// 服务端按请求的Host生成链接，改用配置的服务地址以保留 https 和路径前缀
+	return c.server + "/" + path.Base(result.ShortLink), nil
+}
+
+// writeShareForm 写入创建内容的表单
+func writeShareForm(form *multipart.Writer, opts ShareOptions, upload Upload) error {
+	fields := [][2]string{
+		{"type", opts.Type},
+		{"title", opts.Title},
+		{"is_public", strconv.FormatBool(opts.Public)},
+		{"expires_in", opts.Expire},
+		{"language", opts.Language},
+		{"tags", opts.Tags},
+		{"password", opts.Password},
+	}
+	if opts.MaxViews > 0 {
+		fields = append(fields, [2]string{"max_views", strconv.Itoa(opts.MaxViews)})
+	}
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+
+	var part io.Writer
+	var err error
+	switch opts.Type {
+	case "image", "file":
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, escapeQuotes(upload.Name)))
+		header.Set("Content-Type", upload.MIMEType)
+		part, err = form.CreatePart(header)
+	default:
+		part, err = form.CreateFormField("content")
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, upload.Body); err != nil {
+		return fmt.Errorf("读取内容失败: %v", err)
+	}
+
+	return form.Close()
+}
+
+// escapeQuotes 转义表单文件名中的引号和反斜杠
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
+
+// List 列出当前账户的内容
+func (c *Client) List(opts ListOptions) (ContentList, error) {
+	query := url.Values{}
+	if opts.Query != "" {
+		query.Set("query", opts.Query)
+	}
+	if opts.Type != "" {
+		query.Set("type", opts.Type)
+	}
+	if opts.Tag != "" {
+		query.Set("tag", opts.Tag)
+	}
+	if opts.Page > 0 {
+		query.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(opts.PerPage))
+	}
+
+	var list ContentList
+	req, err := c.newRequest(http.MethodGet, "/api/contents", query, nil)
+	if err != nil {
+		return list, err
+	}
+	err = c.do(req, &list)
+	return list, err
+}
+
+// Delete 删除当前账户的内容
+func (c *Client) Delete(shortID string) error {
+	req, err := c.newRequest(http.MethodDelete, "/api/contents", url.Values{"content_id": {shortID}}, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, nil)
+}
+
+// newRequest 创建携带API令牌的请求
+func (c *Client) newRequest(method string, apiPath string, query url.Values, body io.Reader) (*http.Request, error) {
+	target := c.server + apiPath
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	return req, nil
+}
+
+// do 发送请求并解析JSON响应，服务端返回错误时使用响应中的 error 字段作为错误信息
+func (c *Client) do(req *http.Request, result interface{}) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求服务失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&failure) == nil && failure.Error != "" {
+			return fmt.Errorf("%s（HTTP %d）", failure.Error, resp.StatusCode)
+		}
+		return fmt.Errorf("服务返回 %s", resp.Status)
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Settings 命令行客户端的配置，保存在用户配置目录下的 sharesth/cli.yaml 中
+type Settings struct {
+	Server string `yaml:"server"` // 服务地址，如 "https://share.example.com"
+	Token  string `yaml:"token"`  // API令牌
+}
+
+// SettingsPath 返回配置文件路径，可以通过 SHARESTH_CLI_CONFIG 环境变量指定
+func SettingsPath() (string, error) {
+	if path := os.Getenv("SHARESTH_CLI_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户配置目录失败: %v", err)
+	}
+	return filepath.Join(dir, "sharesth", "cli.yaml"), nil
+}
+
+// LoadSettings 读取配置文件，文件不存在时返回空配置
+// 环境变量 SHARESTH_SERVER 和 SHARESTH_TOKEN 覆盖文件中的值
+func LoadSettings() (Settings, error) {
+	var settings Settings
+
+	path, err := SettingsPath()
+	if err != nil {
+		return settings, err
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return settings, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(raw, &settings); err != nil {
+			return settings, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+		}
+	}
+
+	if server := os.Getenv("SHARESTH_SERVER"); server != "" {
+		settings.Server = server
+	}
+	if token := os.Getenv("SHARESTH_TOKEN"); token != "" {
+		settings.Token = token
+	}
+	settings.Server = strings.TrimRight(settings.Server, "/")
+
+	return settings, nil
+}
+
+// SaveSettings 保存配置文件，文件中包含API令牌，仅当前用户可读写
+func SaveSettings(settings Settings) (string, error) {
+	path, err := SettingsPath()
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := yaml.Marshal(settings)
+	if err != nil {
+		return "", fmt.Errorf("序列化配置失败: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		return "", fmt.Errorf("保存配置文件失败: %v", err)
+	}
+	// 覆盖已存在的文件时 WriteFile 不修改权限
+	if err := os.Chmod(path, 0600); err != nil {
+		return "", fmt.Errorf("设置配置文件权限失败: %v", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,130 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"sharesth/models"
+)
+
+// 账户相关常量
+const (
+	// 密码最小长度
+	MinPasswordLength = 8
+	// 密码最大长度（bcrypt 只处理前72字节）
+	MaxPasswordLength = 72
+)
+
+// 用户名只允许字母、数字、下划线和短横线，长度3-32
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{3,32}$`)
+
+// 用于用户不存在时的哈希比较
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("sharesth-dummy-password"), bcrypt.DefaultCost)
+
+// 账户相关错误
+var (
+	ErrInvalidUsername   = errors.New("用户名只能包含字母、数字、下划线和短横线，长度为3-32个字符")
+	ErrInvalidPassword   = fmt.Errorf("密码长度必须在%d-%d个字符之间", MinPasswordLength, MaxPasswordLength)
+	ErrUsernameTaken     = errors.New("用户名已被占用")
+	ErrInvalidCredential = errors.New("用户名或密码错误")
+)
+
+// CreateUser 创建新账户，userID 为账户要认领的内容来源标识
+func CreateUser(username string, password string, userID string) (models.User, error) {
+	username = strings.TrimSpace(username)
+	if !usernamePattern.MatchString(username) {
+		return models.User{}, ErrInvalidUsername
+	}
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return models.User{}, ErrInvalidPassword
+	}
+
+	// 检查用户名是否已存在，配置为管理员的用户名视为已占用
+	var count int64
+	DB.Model(&models.User{}).Where("username = ?", username).Count(&count)
+	if count > 0 || isAdminUsername(username) {
+		return models.User{}, ErrUsernameTaken
+	}
+
+	// 生成密码哈希
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, fmt.Errorf("生成密码哈希失败: %v", err)
+	}
+
+	user := models.User{
+		Username:     username,
+		PasswordHash: string(hash),
+		UserID:       userID,
+		CreatedAt:    time.Now(),
+		LastLoginAt:  time.Now(),
+	}
+	if err := DB.Create(&user).Error; err != nil {
+		return models.User{}, fmt.Errorf("创建账户失败: %v", err)
+	}
+
+	return user, nil
+}
+
+// AuthenticateUser 校验用户名和密码，成功时返回账户并更新最近登录时间
+func AuthenticateUser(username string, password string) (models.User, error) {
+	var user models.User
+	result := DB.Where("username = ?", strings.TrimSpace(username)).First(&user)
+	if result.Error != nil {
+		// 即使用户不存在也执行一次哈希比较，避免通过响应时间枚举用户名
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return models.User{}, ErrInvalidCredential
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return models.User{}, ErrInvalidCredential
+	}
+
+	user.LastLoginAt = time.Now()
+	DB.Model(&user).Update("last_login_at", user.LastLoginAt)
+
+	return user, nil
+}
+
+// FindUserByID 根据账户主键查找账户
+func FindUserByID(id uint) (models.User, bool) {
+	var user models.User
+	if err := DB.First(&user, id).Error; err != nil {
+		return models.User{}, false
+	}
+
+	return user, true
+}
+
+// FindUserByUserID 根据内容来源标识查找认领它的账户
+func FindUserByUserID(userID string) (models.User, bool) {
+	var user models.User
+	if err := DB.Where("user_id = ?", userID).First(&user).Error; err != nil {
+		return models.User{}, false
+	}
+
+	return user, true
+}
+
+// IsUserIDClaimed 判断指定的用户ID是否已被某个账户认领
+func IsUserIDClaimed(userID string) bool {
+	var count int64
+	DB.Model(&models.User{}).Where("user_id = ?", userID).Count(&count)
+	return count > 0
+}
+
+// GetAllAccountUserIDs 获取所有账户认领的用户ID
+func GetAllAccountUserIDs() []string {
+	var userIDs []string
+	if err := DB.Model(&models.User{}).Pluck("user_id", &userIDs).Error; err != nil {
+		log.Printf("加载账户用户ID失败: %v", err)
+	}
+
+	return userIDs
+}
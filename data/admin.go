@@ -0,0 +1,72 @@
+package data
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"sharesth/config"
+	"sharesth/models"
+)
+
+var (
+	// 管理员账户的用户名
+	adminUsers []string
+	// 管理员账户的ID，由 LoadAdminAccounts 根据用户名解析
+	adminAccountIDs = make(map[uint]bool)
+	// 无需账户的管理员令牌
+	adminTokens []string
+)
+
+// InitAdmins 设置管理员账户和管理员令牌
+func InitAdmins(cfg config.SecurityConfig) {
+	adminUsers = cfg.AdminUsers
+	adminTokens = cfg.AdminTokens
+}
+
+// LoadAdminAccounts 将配置的管理员用户名解析为账户ID，管理员权限绑定到启动时已存在的账户
+// 有用户名尚未注册时返回错误，否则任何人都可以注册该用户名成为管理员
+func LoadAdminAccounts() error {
+	ids := make(map[uint]bool, len(adminUsers))
+	var missing []string
+	for _, username := range adminUsers {
+		var user models.User
+		if err := DB.Where("username = ?", username).First(&user).Error; err != nil {
+			missing = append(missing, username)
+			continue
+		}
+		ids[user.ID] = true
+	}
+	adminAccountIDs = ids
+
+	if len(missing) > 0 {
+		return fmt.Errorf("security.admin_users 中的账户尚未注册: %s，请先注册这些账户再将其设为管理员", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// IsAdmin 判断账户是否为管理员
+func IsAdmin(user models.User) bool {
+	return adminAccountIDs[user.ID]
+}
+
+// isAdminUsername 判断用户名是否配置为管理员，这些用户名不能通过注册获得
+func isAdminUsername(username string) bool {
+	for _, candidate := range adminUsers {
+		if candidate == username {
+			return true
+		}
+	}
+	return false
+}
+
+// FindAdminToken 判断令牌是否为配置的管理员令牌，用于脚本等无需账户的场景
+// 返回在审计日志中代表该令牌的名称，如 "token#1"
+func FindAdminToken(token string) (string, bool) {
+	for i, candidate := range adminTokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			return fmt.Sprintf("token#%d", i+1), true
+		}
+	}
+	return "", false
+}
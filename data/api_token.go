@@ -0,0 +1,147 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"sharesth/models"
+)
+
+// API令牌授权范围
+const (
+	ScopeRead   = "read"
+	ScopeWrite  = "write"
+	ScopeDelete = "delete"
+	ScopeAdmin  = "admin" // 管理员账户通过API令牌调用管理接口
+)
+
+// API令牌相关常量
+const (
+	// 令牌前缀，便于在日志和配置中识别
+	APITokenPrefix = "sst_"
+	// 最近使用时间的更新间隔，避免每次请求都写数据库
+	apiTokenTouchInterval = time.Minute
+)
+
+// 未指定授权范围时的默认值
+var DefaultAPITokenScopes = []string{ScopeRead, ScopeWrite}
+
+// 所有合法的授权范围
+var validScopes = map[string]bool{ScopeRead: true, ScopeWrite: true, ScopeDelete: true, ScopeAdmin: true}
+
+// ErrInvalidScope 授权范围无效
+var ErrInvalidScope = errors.New("无效的授权范围，只支持 read、write、delete、admin")
+
+// CreateAPIToken 为账户创建API令牌，返回令牌记录和令牌原文（原文只在创建时返回一次）
+func CreateAPIToken(accountID uint, name string, scopes []string) (models.APIToken, string, error) {
+	scopes = FilterEmpty(scopes)
+	if len(scopes) == 0 {
+		scopes = DefaultAPITokenScopes
+	}
+	for _, scope := range scopes {
+		if !validScopes[scope] {
+			return models.APIToken{}, "", ErrInvalidScope
+		}
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "未命名令牌"
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	secret, err := generateToken(20)
+	if err != nil {
+		return models.APIToken{}, "", err
+	}
+	token := APITokenPrefix + secret
+
+	apiToken := models.APIToken{
+		AccountID: accountID,
+		Name:      name,
+		TokenHash: hashToken(token),
+		Prefix:    token[:len(APITokenPrefix)+6],
+		Scopes:    strings.Join(scopes, ","),
+		CreatedAt: time.Now(),
+	}
+	if err := DB.Create(&apiToken).Error; err != nil {
+		return models.APIToken{}, "", fmt.Errorf("保存API令牌失败: %v", err)
+	}
+
+	return apiToken, token, nil
+}
+
+// FindAPITokens 查找账户的所有API令牌
+func FindAPITokens(accountID uint) []models.APIToken {
+	tokens := make([]models.APIToken, 0)
+	DB.Where("account_id = ?", accountID).Order("created_at DESC").Find(&tokens)
+	return tokens
+}
+
+// RevokeAPIToken 吊销账户的指定API令牌
+func RevokeAPIToken(accountID uint, tokenID uint) error {
+	result := DB.Where("id = ? AND account_id = ?", tokenID, accountID).Delete(&models.APIToken{})
+	if result.Error != nil {
+		return fmt.Errorf("吊销API令牌失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("令牌不存在或无权吊销")
+	}
+
+	return nil
+}
+
+// FindAPIToken 根据令牌原文查找API令牌，并更新最近使用时间
+func FindAPIToken(token string) (models.APIToken, bool) {
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return models.APIToken{}, false
+	}
+
+	var apiToken models.APIToken
+	if err := DB.Where("token_hash = ?", hashToken(token)).First(&apiToken).Error; err != nil {
+		return models.APIToken{}, false
+	}
+
+	if time.Since(apiToken.LastUsedAt) > apiTokenTouchInterval {
+		apiToken.LastUsedAt = time.Now()
+		DB.Model(&apiToken).Update("last_used_at", apiToken.LastUsedAt)
+	}
+
+	return apiToken, true
+}
+
+// GetBearerToken 从请求的 Authorization 头中提取 Bearer 令牌
+func GetBearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return "", false
+	}
+
+	token := strings.TrimSpace(header[7:])
+	return token, token != ""
+}
+
+// GetRequestAPIToken 获取请求携带的有效API令牌
+func GetRequestAPIToken(r *http.Request) (models.APIToken, bool) {
+	token, found := GetBearerToken(r)
+	if !found {
+		return models.APIToken{}, false
+	}
+
+	return FindAPIToken(token)
+}
+
+// GetAPITokenUser 获取请求携带的API令牌对应的账户
+func GetAPITokenUser(r *http.Request) (models.User, bool) {
+	apiToken, found := GetRequestAPIToken(r)
+	if !found {
+		return models.User{}, false
+	}
+
+	return FindUserByID(apiToken.AccountID)
+}
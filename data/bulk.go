@@ -0,0 +1,114 @@
+package data
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"sharesth/models"
+)
+
+// 单次批量操作最多处理的内容数
+const MaxBulkItems = 500
+
+// BulkResult 批量操作中单个内容的处理结果
+type BulkResult struct {
+	ShortID string `json:"short_id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkDeleteContents 批量删除指定来源的内容
+func BulkDeleteContents(source string, shortIDs []string) ([]BulkResult, error) {
+	var paths []string
+	results, err := runBulkOperation(source, shortIDs, func(tx *gorm.DB, content *models.Content) error {
+		contentPaths, err := deleteContentRecords(tx, *content)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, contentPaths...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 事务提交后再删除不再被引用的上传文件
+	releaseUploadedFiles(paths)
+
+	return results, nil
+}
+
+// BulkUpdateContents 批量修改指定来源的内容，update 返回错误时该内容保持不变
+func BulkUpdateContents(source string, shortIDs []string, update func(content *models.Content) error) ([]BulkResult, error) {
+	return runBulkOperation(source, shortIDs, func(tx *gorm.DB, content *models.Content) error {
+		if err := update(content); err != nil {
+			return err
+		}
+		return saveContentRevisionTx(tx, content, source, "")
+	})
+}
+
+// BulkAddContentTag 为指定来源的内容批量添加标签，已有该标签的内容保持不变
+func BulkAddContentTag(source string, shortIDs []string, tag models.Tag) ([]BulkResult, error) {
+	return runBulkOperation(source, shortIDs, func(tx *gorm.DB, content *models.Content) error {
+		for _, existing := range content.Tags {
+			if existing.ID == tag.ID {
+				return nil
+			}
+		}
+		if len(content.Tags) >= MaxTagsPerContent {
+			return fmt.Errorf("标签过多，每个内容最多%d个标签", MaxTagsPerContent)
+		}
+
+		if err := tx.Model(content).Association("Tags").Append(&tag); err != nil {
+			return fmt.Errorf("添加标签失败: %v", err)
+		}
+		return nil
+	})
+}
+
+// runBulkOperation 在同一个事务中对每个内容执行操作，并逐项返回处理结果
+// 每个内容在单独的保存点中处理，不存在、不属于该来源或处理失败的内容会回滚自身的修改，不影响其他内容
+func runBulkOperation(source string, shortIDs []string, apply func(tx *gorm.DB, content *models.Content) error) ([]BulkResult, error) {
+	if len(shortIDs) == 0 {
+		return nil, fmt.Errorf("未提供内容ID")
+	}
+	if len(shortIDs) > MaxBulkItems {
+		return nil, fmt.Errorf("单次最多处理%d个内容", MaxBulkItems)
+	}
+
+	results := make([]BulkResult, 0, len(shortIDs))
+	seen := make(map[string]bool, len(shortIDs))
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		for _, shortID := range shortIDs {
+			// 重复的ID只处理一次
+			if seen[shortID] {
+				continue
+			}
+			seen[shortID] = true
+			result := BulkResult{ShortID: shortID}
+
+			err := tx.Transaction(func(itemTx *gorm.DB) error {
+				var content models.Content
+				if err := itemTx.Preload("Tags").Where("short_id = ? AND source = ?", shortID, source).First(&content).Error; err != nil {
+					return fmt.Errorf("内容不存在或无权修改")
+				}
+				return apply(itemTx, &content)
+			})
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Success = true
+			}
+
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("批量操作失败: %v", err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"sharesth/cache"
+	"sharesth/config"
+)
+
+var (
+	// UserIDCache 浏览器指纹对应用户ID的缓存
+	UserIDCache cache.Cache = cache.NewNoop()
+	// 用户ID的缓存时间
+	userIDCacheExpiration = 24 * time.Hour
+)
+
+// InitCache 根据配置初始化用户ID缓存
+// backend 为 "auto" 时Redis可用则使用Redis，否则退回到进程内缓存；为 "redis" 时Redis不可用则返回错误
+func InitCache(cfg config.CacheConfig, redisCfg config.RedisConfig) error {
+	userIDCacheExpiration = cfg.UserIDTTL.Duration
+
+	switch cfg.Backend {
+	case "", "auto":
+		if err := InitRedisClient(redisCfg); err != nil {
+			log.Printf("%v，改用进程内缓存", err)
+			UserIDCache = cache.NewLRU(cfg.Size)
+		} else {
+			UserIDCache = cache.NewRedis(RedisClient)
+		}
+	case "redis":
+		if err := InitRedisClient(redisCfg); err != nil {
+			return err
+		}
+		UserIDCache = cache.NewRedis(RedisClient)
+	case "memory":
+		UserIDCache = cache.NewLRU(cfg.Size)
+	case "none":
+		UserIDCache = cache.NewNoop()
+	default:
+		return fmt.Errorf("不支持的缓存后端: %s", cfg.Backend)
+	}
+
+	log.Printf("用户ID缓存后端: %s", UserIDCache.Name())
+	return nil
+}
+
+// userIDCacheKey 返回浏览器哈希对应的缓存键
+func userIDCacheKey(browserHash string) string {
+	return "user_id:" + browserHash
+}
+
+// GetCachedUserID 从缓存获取浏览器哈希对应的用户ID，命中时刷新过期时间
+func GetCachedUserID(browserHash string) (string, bool) {
+	key := userIDCacheKey(browserHash)
+
+	userID, err := UserIDCache.Get(Ctx, key)
+	if errors.Is(err, cache.ErrMiss) {
+		return "", false
+	} else if err != nil {
+		log.Printf("从缓存获取用户ID失败: %v", err)
+		return "", false
+	}
+
+	// 找到用户ID，更新过期时间
+	if err := UserIDCache.Set(Ctx, key, userID, userIDCacheExpiration); err != nil {
+		log.Printf("刷新用户ID缓存失败: %v", err)
+	}
+	return userID, true
+}
+
+// CacheUserID 缓存浏览器哈希对应的用户ID
+func CacheUserID(browserHash string, userID string) {
+	if err := UserIDCache.Set(Ctx, userIDCacheKey(browserHash), userID, userIDCacheExpiration); err != nil {
+		log.Printf("缓存用户ID失败: %v", err)
+	}
+}
+
+// DeleteCachedUserID 删除浏览器哈希对应的用户ID缓存
+func DeleteCachedUserID(browserHash string) {
+	if err := UserIDCache.Delete(Ctx, userIDCacheKey(browserHash)); err != nil {
+		log.Printf("删除用户ID缓存失败: %v", err)
+	}
+}
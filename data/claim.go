@@ -0,0 +1,152 @@
+package data
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sharesth/models"
+)
+
+// 认领码相关常量
+const (
+	// 认领码长度
+	ClaimCodeLength = 8
+	// 认领码有效期
+	ClaimCodeExpiration = 10 * time.Minute
+	// 认领码字符集，去掉了容易混淆的 0/O、1/I
+	claimCodeChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// 认领相关错误
+var (
+	ErrInvalidClaimCode = errors.New("认领码无效或已过期")
+	ErrAlreadyMerged    = errors.New("当前浏览器已属于该账户，无需关联")
+)
+
+// normalizeClaimCode 统一认领码格式，允许用户输入小写和空格
+func normalizeClaimCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
+
+// CreateClaimCode 为账户生成一次性认领码，返回认领码原文和过期时间
+func CreateClaimCode(accountID uint) (string, time.Time, error) {
+	codeBytes := make([]byte, ClaimCodeLength)
+	for i := range codeBytes {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(claimCodeChars))))
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("生成认领码失败: %v", err)
+		}
+		codeBytes[i] = claimCodeChars[n.Int64()]
+	}
+	code := string(codeBytes)
+
+	claimCode := models.ClaimCode{
+		CodeHash:  hashToken(code),
+		AccountID: accountID,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(ClaimCodeExpiration),
+	}
+	if err := DB.Create(&claimCode).Error; err != nil {
+		return "", time.Time{}, fmt.Errorf("保存认领码失败: %v", err)
+	}
+
+	return code, claimCode.ExpiresAt, nil
+}
+
+// RedeemClaimCode 兑换认领码，将 fromUserID 名下的所有内容转移到生成认领码的账户
+// 返回目标账户和转移的内容数量
+func RedeemClaimCode(code string, fromUserID string, browserInfo string) (models.User, int64, error) {
+	var (
+		user          models.User
+		moved         int64
+		browserHashes []string
+	)
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		// 查找并作废认领码，保证只能使用一次
+		var claimCode models.ClaimCode
+		result := tx.Where("code_hash = ? AND expires_at > ?", hashToken(normalizeClaimCode(code)), time.Now()).First(&claimCode)
+		if result.Error != nil {
+			return ErrInvalidClaimCode
+		}
+		if err := tx.Delete(&claimCode).Error; err != nil {
+			return fmt.Errorf("作废认领码失败: %v", err)
+		}
+
+		if err := tx.First(&user, claimCode.AccountID).Error; err != nil {
+			return ErrInvalidClaimCode
+		}
+		if user.UserID == fromUserID {
+			return ErrAlreadyMerged
+		}
+
+		// 将原用户ID名下的内容转移到账户
+		result = tx.Model(&models.Content{}).Where("source = ?", fromUserID).Update("source", user.UserID)
+		if result.Error != nil {
+			return fmt.Errorf("转移内容失败: %v", result.Error)
+		}
+		moved = result.RowsAffected
+
+		// 合集随内容一起转移
+		if err := tx.Model(&models.Collection{}).Where("source = ?", fromUserID).Update("source", user.UserID).Error; err != nil {
+			return fmt.Errorf("转移合集失败: %v", err)
+		}
+
+		// 原用户ID不再使用，删除与之关联的浏览器指纹
+		if err := tx.Model(&models.UserFingerprint{}).Where("user_id = ?", fromUserID).Pluck("browser_hash", &browserHashes).Error; err != nil {
+			return fmt.Errorf("查询浏览器指纹失败: %v", err)
+		}
+		if err := tx.Where("user_id = ?", fromUserID).Delete(&models.UserFingerprint{}).Error; err != nil {
+			return fmt.Errorf("删除浏览器指纹失败: %v", err)
+		}
+
+		// 记录审计信息
+		merge := models.IdentityMerge{
+			AccountID:    user.ID,
+			FromUserID:   fromUserID,
+			ToUserID:     user.UserID,
+			ContentCount: moved,
+			BrowserInfo:  browserInfo,
+			CreatedAt:    time.Now(),
+		}
+		if err := tx.Create(&merge).Error; err != nil {
+			return fmt.Errorf("保存合并记录失败: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.User{}, 0, err
+	}
+
+	// 事务提交后同步缓存：删除缓存中的映射并释放内存中的用户ID
+	for _, browserHash := range browserHashes {
+		DeleteCachedUserID(browserHash)
+	}
+	ReleaseUserID(fromUserID)
+
+	log.Printf("用户ID %s 已合并到账户 %s (%s)，转移内容 %d 条", fromUserID, user.Username, user.UserID, moved)
+	return user, moved, nil
+}
+
+// FindIdentityMerges 查找账户的身份合并记录
+func FindIdentityMerges(accountID uint) []models.IdentityMerge {
+	merges := make([]models.IdentityMerge, 0)
+	DB.Where("account_id = ?", accountID).Order("created_at DESC").Find(&merges)
+	return merges
+}
+
+// DeleteExpiredClaimCodes 清理已过期的认领码
+func DeleteExpiredClaimCodes() {
+	result := DB.Where("expires_at <= ?", time.Now()).Delete(&models.ClaimCode{})
+	if result.Error != nil {
+		log.Printf("清理过期认领码失败: %v", result.Error)
+	}
+}
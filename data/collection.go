@@ -0,0 +1,282 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sharesth/models"
+)
+
+// 每个合集最多包含的内容数
+const MaxCollectionItems = 100
+
+// ErrCollectionNotFound 合集不存在或不属于当前用户
+var ErrCollectionNotFound = errors.New("合集不存在或无权访问")
+
+// ErrCollectionLocked 合集被管理员取消公开，管理员解除锁定前不能重新公开
+var ErrCollectionLocked = errors.New("合集已被管理员取消公开，暂时不能公开")
+
+// CreateCollection 保存新合集，contentIDs 为按顺序排列的内容短链接ID
+func CreateCollection(collection *models.Collection, contentIDs []string) error {
+	collection.ShortID = NewShortID()
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(collection).Error; err != nil {
+			return err
+		}
+		return replaceCollectionItems(tx, collection, contentIDs)
+	})
+	if err != nil {
+		return fmt.Errorf("保存合集失败: %v", err)
+	}
+
+	return nil
+}
+
+// LoadCollection 根据短链接ID加载合集
+func LoadCollection(shortID string) (models.Collection, error) {
+	var collection models.Collection
+	if err := DB.Where("short_id = ?", shortID).First(&collection).Error; err != nil {
+		return models.Collection{}, ErrCollectionNotFound
+	}
+	return collection, nil
+}
+
+// LoadCollectionBySource 根据短链接ID和来源加载合集
+func LoadCollectionBySource(shortID string, source string) (models.Collection, error) {
+	var collection models.Collection
+	if err := DB.Where("short_id = ? AND source = ?", shortID, source).First(&collection).Error; err != nil {
+		return models.Collection{}, ErrCollectionNotFound
+	}
+	return collection, nil
+}
+
+// UpdateCollection 保存合集的标题、描述和公开状态，被锁定的合集不能重新公开
+func UpdateCollection(collection *models.Collection) error {
+	if collection.Locked && collection.IsPublic {
+		return ErrCollectionLocked
+	}
+
+	collection.UpdateTime = time.Now()
+	if err := DB.Save(collection).Error; err != nil {
+		return fmt.Errorf("更新合集失败: %v", err)
+	}
+	return nil
+}
+
+// DeleteCollection 删除合集，合集中的内容本身不受影响
+func DeleteCollection(collection models.Collection) error {
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&models.CollectionItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&collection).Error
+	})
+	if err != nil {
+		return fmt.Errorf("删除合集失败: %v", err)
+	}
+	return nil
+}
+
+// SetCollectionItems 按给定顺序替换合集中的全部内容，可用于调整顺序
+func SetCollectionItems(collection *models.Collection, contentIDs []string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := replaceCollectionItems(tx, collection, contentIDs); err != nil {
+			return err
+		}
+		return touchCollection(tx, collection)
+	})
+}
+
+// AddCollectionItem 将内容追加到合集末尾，内容已在合集中时不做修改
+func AddCollectionItem(collection *models.Collection, contentID string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		contents, err := resolveCollectionContents(tx, collection.Source, []string{contentID})
+		if err != nil {
+			return err
+		}
+		if len(contents) == 0 {
+			return fmt.Errorf("未提供内容ID")
+		}
+
+		var existing models.CollectionItem
+		if tx.Where("collection_id = ? AND content_id = ?", collection.ID, contents[0].ID).First(&existing).Error == nil {
+			return nil
+		}
+
+		var count int64
+		tx.Model(&models.CollectionItem{}).Where("collection_id = ?", collection.ID).Count(&count)
+		if count >= MaxCollectionItems {
+			return fmt.Errorf("合集最多包含%d个内容", MaxCollectionItems)
+		}
+
+		var last struct{ Position int }
+		tx.Model(&models.CollectionItem{}).Select("COALESCE(MAX(position), 0) AS position").Where("collection_id = ?", collection.ID).Scan(&last)
+
+		item := models.CollectionItem{
+			CollectionID: collection.ID,
+			ContentID:    contents[0].ID,
+			Position:     last.Position + 1,
+			CreatedAt:    time.Now(),
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return fmt.Errorf("添加合集内容失败: %v", err)
+		}
+
+		return touchCollection(tx, collection)
+	})
+}
+
+// RemoveCollectionItem 从合集中移除内容
+func RemoveCollectionItem(collection *models.Collection, contentID string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("collection_id = ? AND content_id IN (?)", collection.ID,
+			tx.Model(&models.Content{}).Select("id").Where("short_id = ?", contentID)).
+			Delete(&models.CollectionItem{})
+		if result.Error != nil {
+			return fmt.Errorf("移除合集内容失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("内容 %s 不在合集中", contentID)
+		}
+
+		return touchCollection(tx, collection)
+	})
+}
+
+// FindCollectionItems 按顺序返回合集中的内容
+// includePrivate 为 false 时只返回公开的内容，已过期的内容始终不返回
+func FindCollectionItems(collection models.Collection, includePrivate bool) []map[string]interface{} {
+	var contents []models.Content
+	db := DB.Scopes(notExpired).
+		Joins("JOIN collection_items ON collection_items.content_id = contents.id").
+		Where("collection_items.collection_id = ?", collection.ID)
+	if !includePrivate {
+		db = db.Where("contents.is_public = ?", true)
+	}
+	db.Preload("Tags").Order("collection_items.position").Find(&contents)
+
+	// 初始化为空数组而非nil
+	results := make([]map[string]interface{}, 0, len(contents))
+
+	for i, content := range contents {
+		item := map[string]interface{}{
+			"position":   i + 1,
+			"short_id":   content.ShortID,
+			"type":       content.Type,
+			"createTime": content.CreateTime,
+			"link":       content.ShortID,
+			"title":      content.Title,
+			"is_public":  content.IsPublic,
+			"tags":       content.TagNames(),
+		}
+
+		// 根据内容类型添加不同的额外字段
+		addContentPreview(item, content)
+
+		results = append(results, item)
+	}
+
+	return results
+}
+
+// FindCollectionsBySource 查找指定来源的所有合集及其内容数量
+func FindCollectionsBySource(source string) []map[string]interface{} {
+	var collections []struct {
+		models.Collection
+		ItemCount int64 `gorm:"column:item_count"`
+	}
+	DB.Model(&models.Collection{}).
+		Select("collections.*, (SELECT count(*) FROM collection_items WHERE collection_items.collection_id = collections.id) AS item_count").
+		Where("source = ?", source).
+		Order("update_time DESC").
+		Scan(&collections)
+
+	// 初始化为空数组而非nil
+	results := make([]map[string]interface{}, 0, len(collections))
+
+	for _, collection := range collections {
+		results = append(results, map[string]interface{}{
+			"short_id":    collection.ShortID,
+			"title":       collection.Title,
+			"description": collection.Description,
+			"is_public":   collection.IsPublic,
+			"locked":      collection.Locked,
+			"createTime":  collection.CreateTime,
+			"updateTime":  collection.UpdateTime,
+			"link":        "c/" + collection.ShortID,
+			"item_count":  collection.ItemCount,
+		})
+	}
+
+	return results
+}
+
+// replaceCollectionItems 删除合集中原有的内容，按顺序写入新的内容
+func replaceCollectionItems(tx *gorm.DB, collection *models.Collection, contentIDs []string) error {
+	contents, err := resolveCollectionContents(tx, collection.Source, contentIDs)
+	if err != nil {
+		return err
+	}
+	if len(contents) > MaxCollectionItems {
+		return fmt.Errorf("合集最多包含%d个内容", MaxCollectionItems)
+	}
+
+	if err := tx.Where("collection_id = ?", collection.ID).Delete(&models.CollectionItem{}).Error; err != nil {
+		return fmt.Errorf("清除合集内容失败: %v", err)
+	}
+	if len(contents) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	items := make([]models.CollectionItem, 0, len(contents))
+	for i, content := range contents {
+		items = append(items, models.CollectionItem{
+			CollectionID: collection.ID,
+			ContentID:    content.ID,
+			Position:     i + 1,
+			CreatedAt:    now,
+		})
+	}
+	if err := tx.Create(&items).Error; err != nil {
+		return fmt.Errorf("保存合集内容失败: %v", err)
+	}
+
+	return nil
+}
+
+// resolveCollectionContents 按顺序查找内容并去除重复，只能添加同一来源的内容
+func resolveCollectionContents(tx *gorm.DB, source string, contentIDs []string) ([]models.Content, error) {
+	contents := make([]models.Content, 0, len(contentIDs))
+	seen := make(map[string]bool)
+
+	for _, contentID := range contentIDs {
+		if contentID == "" || seen[contentID] {
+			continue
+		}
+		seen[contentID] = true
+
+		var content models.Content
+		if err := tx.Where("short_id = ? AND source = ?", contentID, source).First(&content).Error; err != nil {
+			return nil, fmt.Errorf("内容 %s 不存在或不属于当前用户", contentID)
+		}
+		contents = append(contents, content)
+	}
+
+	return contents, nil
+}
+
+// touchCollection 更新合集的修改时间
+func touchCollection(tx *gorm.DB, collection *models.Collection) error {
+	collection.UpdateTime = time.Now()
+	return tx.Model(collection).Update("update_time", collection.UpdateTime).Error
+}
+
+// deleteContentCollectionItems 将内容从所有合集中移除
+func deleteContentCollectionItems(tx *gorm.DB, contentID uint) error {
+	return tx.Where("content_id = ?", contentID).Delete(&models.CollectionItem{}).Error
+}
@@ -1,20 +1,93 @@
 package data
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"gorm.io/gorm"
+
+	"sharesth/config"
 	"sharesth/models"
+	"sharesth/utils"
+)
+
+// ErrContentExpired 内容已过期或访问次数已用尽
+var ErrContentExpired = errors.New("内容已过期或访问次数已用尽")
+
+var (
+	// 新内容和合集的短链接ID长度
+	shortIDLength = 8
+	// 自动隐藏公开内容所需的待处理举报数
+	reportHideThreshold = 3
 )
 
+// InitContentSettings 设置内容相关的配置
+func InitContentSettings(cfg config.ContentConfig) {
+	shortIDLength = cfg.ShortIDLength
+	reportHideThreshold = cfg.ReportThreshold
+}
+
+// NewShortID 生成配置长度的随机短链接ID
+func NewShortID() string {
+	return utils.GenerateShortID(shortIDLength)
+}
+
+// notExpired 过滤掉已过期或访问次数已用尽的内容
+func notExpired(db *gorm.DB) *gorm.DB {
+	return db.Where("(expires_at IS NULL OR expires_at > ?) AND (max_views = 0 OR view_count < max_views)", time.Now())
+}
+
+// addContentPreview 根据内容类型为列表项添加摘要或图片地址，设置了访问密码或访问次数限制的内容不展示预览
+func addContentPreview(item map[string]interface{}, content models.Content) {
+	item["protected"] = content.IsProtected()
+	if content.HidesPreview() {
+		return
+	}
+
+	if content.IsTextual() {
+		// 为文本内容添加summary字段，截取部分内容作为摘要
+		if len(content.Data) > 200 {
+			item["summary"] = content.Data[:200] + "..."
+		} else {
+			item["summary"] = content.Data
+		}
+		if content.Type == "code" {
+			item["language"] = content.Language
+		}
+	} else if content.Type == "image" {
+		// 为图片内容添加相关URL
+		item["thumbnail_url"] = content.Data
+		item["image_url"] = content.Data
+		item["content_url"] = content.Data
+	} else if content.Type == "file" {
+		// 为文件内容添加文件信息
+		item["summary"] = fmt.Sprintf("%s (%s)", content.FileName, utils.FormatFileSize(content.FileSize))
+		item["file_name"] = content.FileName
+		item["file_size"] = content.FileSize
+		item["mime_type"] = content.MimeType
+		item["content_url"] = "/" + content.ShortID + "/download"
+	}
+}
+
 // SaveContent 保存内容到数据库
 func SaveContent(shortID string, content models.Content) error {
 	// 设置短链接ID
 	content.ShortID = shortID
 
-	// 保存到数据库
-	result := DB.Create(&content)
-	if result.Error != nil {
-		return fmt.Errorf("保存内容到数据库失败: %v", result.Error)
+	// 保存到数据库，同时记录第一个版本
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&content).Error; err != nil {
+			return err
+		}
+		if err := addUploadReferences(tx, content.ID, content.Type, content.Data); err != nil {
+			return err
+		}
+		return createRevision(tx, content, content.Source, "", content.CreateTime)
+	})
+	if err != nil {
+		return fmt.Errorf("保存内容到数据库失败: %v", err)
 	}
 
 	return nil
@@ -23,18 +96,40 @@ func SaveContent(shortID string, content models.Content) error {
 // LoadContent 根据短链接ID加载内容
 func LoadContent(shortID string) (models.Content, error) {
 	var content models.Content
-	result := DB.Where("short_id = ?", shortID).First(&content)
+	result := DB.Preload("Tags").Where("short_id = ?", shortID).First(&content)
 	if result.Error != nil {
 		return models.Content{}, fmt.Errorf("加载内容失败: %v", result.Error)
 	}
 
+	// 已过期或访问次数用尽的内容不再提供访问
+	if content.IsExpired() {
+		return models.Content{}, ErrContentExpired
+	}
+
 	return content, nil
 }
 
+// RecordContentView 记录一次内容访问，访问次数已用尽时返回 ErrContentExpired
+func RecordContentView(content *models.Content) error {
+	// 使用条件更新保证并发访问时不会超过最大访问次数
+	result := DB.Model(&models.Content{}).
+		Where("id = ? AND (max_views = 0 OR view_count < max_views)", content.ID).
+		Update("view_count", gorm.Expr("view_count + 1"))
+	if result.Error != nil {
+		return fmt.Errorf("记录访问次数失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrContentExpired
+	}
+
+	content.ViewCount++
+	return nil
+}
+
 // LoadContentBySource 根据短链接ID和来源加载内容
 func LoadContentBySource(shortID string, source string) (models.Content, error) {
 	var content models.Content
-	result := DB.Where("short_id = ? AND source = ?", shortID, source).First(&content)
+	result := DB.Preload("Tags").Where("short_id = ? AND source = ?", shortID, source).First(&content)
 	if result.Error != nil {
 		return models.Content{}, fmt.Errorf("加载内容失败: %v", result.Error)
 	}
@@ -64,19 +159,7 @@ func FindContentsBySource(source string) []map[string]interface{} {
 		}
 
 		// 根据内容类型添加不同的额外字段
-		if content.Type == "markdown" || content.Type == "text" {
-			// 为文本内容添加summary字段，截取部分内容作为摘要
-			if len(content.Data) > 200 {
-				item["summary"] = content.Data[:200] + "..."
-			} else {
-				item["summary"] = content.Data
-			}
-		} else if content.Type == "image" {
-			// 为图片内容添加相关URL
-			item["thumbnail_url"] = content.Data
-			item["image_url"] = content.Data
-			item["content_url"] = content.Data
-		}
+		addContentPreview(item, content)
 
 		results = append(results, item)
 	}
@@ -87,7 +170,7 @@ func FindContentsBySource(source string) []map[string]interface{} {
 // FindPublicContents 查找所有公开的内容
 func FindPublicContents() []map[string]interface{} {
 	var contents []models.Content
-	DB.Where("is_public = ?", true).Order("create_time DESC").Find(&contents)
+	DB.Scopes(notExpired).Where("is_public = ?", true).Order("create_time DESC").Find(&contents)
 
 	var results []map[string]interface{}
 	// 初始化为空数组而非nil
@@ -105,19 +188,7 @@ func FindPublicContents() []map[string]interface{} {
 		}
 
 		// 根据内容类型添加不同的额外字段
-		if content.Type == "markdown" || content.Type == "text" {
-			// 为文本内容添加summary字段，截取部分内容作为摘要
-			if len(content.Data) > 200 {
-				item["summary"] = content.Data[:200] + "..."
-			} else {
-				item["summary"] = content.Data
-			}
-		} else if content.Type == "image" {
-			// 为图片内容添加相关URL
-			item["thumbnail_url"] = content.Data
-			item["image_url"] = content.Data
-			item["content_url"] = content.Data
-		}
+		addContentPreview(item, content)
 
 		results = append(results, item)
 	}
@@ -125,14 +196,17 @@ func FindPublicContents() []map[string]interface{} {
 	return results
 }
 
-// FindContentsBySourcePaginated 分页查找指定来源的内容，支持标题搜索和类型筛选
-func FindContentsBySourcePaginated(source string, query string, typeFilter string, page int, perPage int) (int64, []map[string]interface{}, map[string]int64) {
+// FindContentsBySourcePaginated 分页查找指定来源的内容，支持全文搜索、类型和标签筛选
+// 返回总数、当前页内容、各类型数量和各标签数量
+func FindContentsBySourcePaginated(source string, query string, typeFilter string, tagFilter string, page int, perPage int) (int64, []map[string]interface{}, map[string]int64, map[string]int64) {
 	var contents []models.Content
 	db := DB.Where("source = ?", source)
 
-	// 如果提供了搜索查询，添加标题搜索条件
+	// 如果提供了搜索查询，按全文索引匹配标题和正文，结果按相关度排序
 	if query != "" {
-		db = db.Where("title LIKE ?", "%"+query+"%")
+		db = searchContents(db, query)
+	} else {
+		db = db.Order("create_time DESC")
 	}
 
 	// 如果提供了类型筛选，添加类型条件
@@ -140,6 +214,11 @@ func FindContentsBySourcePaginated(source string, query string, typeFilter strin
 		db = db.Where("type = ?", typeFilter)
 	}
 
+	// 如果提供了标签筛选，只保留带有该标签的内容
+	if tagFilter != "" {
+		db = db.Scopes(withTag(strings.ToLower(tagFilter)))
+	}
+
 	// 计算总记录数
 	var total int64
 	db.Model(&models.Content{}).Count(&total)
@@ -161,9 +240,17 @@ func FindContentsBySourcePaginated(source string, query string, typeFilter strin
 		typeCounts[stat.Type] = stat.Count
 	}
 
+	// 统计各标签数量
+	tagCounts := countTags(func(db *gorm.DB) *gorm.DB {
+		return db.Where("contents.source = ?", source)
+	})
+
 	// 分页查询
 	offset := (page - 1) * perPage
-	db.Order("create_time DESC").Offset(offset).Limit(perPage).Find(&contents)
+	db.Preload("Tags").Offset(offset).Limit(perPage).Find(&contents)
+
+	// 为搜索结果添加正文中匹配位置的高亮摘要
+	snippets := findSearchSnippets(query, contents)
 
 	// 格式化结果
 	var results []map[string]interface{}
@@ -173,44 +260,44 @@ func FindContentsBySourcePaginated(source string, query string, typeFilter strin
 	for _, content := range contents {
 		// 创建基本结果
 		item := map[string]interface{}{
-			"id":         content.ID,
-			"short_id":   content.ShortID,
-			"type":       content.Type,
-			"createTime": content.CreateTime,
-			"link":       content.ShortID,
-			"title":      content.Title,
-			"is_public":  content.IsPublic,
+			"id":           content.ID,
+			"short_id":     content.ShortID,
+			"type":         content.Type,
+			"createTime":   content.CreateTime,
+			"link":         content.ShortID,
+			"title":        content.Title,
+			"is_public":    content.IsPublic,
+			"under_review": content.UnderReview,
+			"locked":       content.Locked,
+			"expires_at":   content.ExpiresAt,
+			"max_views":    content.MaxViews,
+			"view_count":   content.ViewCount,
+			"tags":         content.TagNames(),
 		}
 
 		// 根据内容类型添加不同的额外字段
-		if content.Type == "markdown" || content.Type == "text" {
-			// 为文本内容添加summary字段，截取部分内容作为摘要
-			if len(content.Data) > 200 {
-				item["summary"] = content.Data[:200] + "..."
-			} else {
-				item["summary"] = content.Data
-			}
-		} else if content.Type == "image" {
-			// 为图片内容添加相关URL
-			item["thumbnail_url"] = content.Data
-			item["image_url"] = content.Data
-			item["content_url"] = content.Data
+		addContentPreview(item, content)
+		if snippet, ok := snippets[content.ID]; ok && !content.HidesPreview() {
+			item["snippet"] = snippet
 		}
 
 		results = append(results, item)
 	}
 
-	return total, results, typeCounts
+	return total, results, typeCounts, tagCounts
 }
 
-// FindPublicContentsPaginated 分页查找公开内容，支持标题搜索和类型筛选
-func FindPublicContentsPaginated(query string, typeFilter string, page int, perPage int) (int64, []map[string]interface{}, map[string]int64) {
+// FindPublicContentsPaginated 分页查找公开内容，支持全文搜索、类型和标签筛选
+// 返回总数、当前页内容、各类型数量和各标签数量
+func FindPublicContentsPaginated(query string, typeFilter string, tagFilter string, page int, perPage int) (int64, []map[string]interface{}, map[string]int64, map[string]int64) {
 	var contents []models.Content
-	db := DB.Where("is_public = ?", true)
+	db := DB.Scopes(notExpired).Where("is_public = ?", true)
 
-	// 如果提供了搜索查询，添加标题搜索条件
+	// 如果提供了搜索查询，按全文索引匹配标题和正文，结果按相关度排序
 	if query != "" {
-		db = db.Where("title LIKE ?", "%"+query+"%")
+		db = searchContents(db, query)
+	} else {
+		db = db.Order("create_time DESC")
 	}
 
 	// 如果提供了类型筛选，添加类型条件
@@ -218,6 +305,11 @@ func FindPublicContentsPaginated(query string, typeFilter string, page int, perP
 		db = db.Where("type = ?", typeFilter)
 	}
 
+	// 如果提供了标签筛选，只保留带有该标签的内容
+	if tagFilter != "" {
+		db = db.Scopes(withTag(strings.ToLower(tagFilter)))
+	}
+
 	// 计算总记录数
 	var total int64
 	db.Model(&models.Content{}).Count(&total)
@@ -230,6 +322,7 @@ func FindPublicContentsPaginated(query string, typeFilter string, page int, perP
 	}
 
 	DB.Model(&models.Content{}).
+		Scopes(notExpired).
 		Where("is_public = ?", true).
 		Select("type, count(*) as count").
 		Group("type").
@@ -239,9 +332,17 @@ func FindPublicContentsPaginated(query string, typeFilter string, page int, perP
 		typeCounts[stat.Type] = stat.Count
 	}
 
+	// 统计各标签数量
+	tagCounts := countTags(notExpired, func(db *gorm.DB) *gorm.DB {
+		return db.Where("contents.is_public = ?", true)
+	})
+
 	// 分页查询
 	offset := (page - 1) * perPage
-	db.Order("create_time DESC").Offset(offset).Limit(perPage).Find(&contents)
+	db.Preload("Tags").Offset(offset).Limit(perPage).Find(&contents)
+
+	// 为搜索结果添加正文中匹配位置的高亮摘要
+	snippets := findSearchSnippets(query, contents)
 
 	// 格式化结果
 	var results []map[string]interface{}
@@ -257,27 +358,19 @@ func FindPublicContentsPaginated(query string, typeFilter string, page int, perP
 			"createTime": content.CreateTime,
 			"link":       content.ShortID,
 			"title":      content.Title,
+			"tags":       content.TagNames(),
 		}
 
 		// 根据内容类型添加不同的额外字段
-		if content.Type == "markdown" || content.Type == "text" {
-			// 为文本内容添加summary字段，截取部分内容作为摘要
-			if len(content.Data) > 200 {
-				item["summary"] = content.Data[:200] + "..."
-			} else {
-				item["summary"] = content.Data
-			}
-		} else if content.Type == "image" {
-			// 为图片内容添加相关URL
-			item["thumbnail_url"] = content.Data
-			item["image_url"] = content.Data
-			item["content_url"] = content.Data
+		addContentPreview(item, content)
+		if snippet, ok := snippets[content.ID]; ok && !content.HidesPreview() {
+			item["snippet"] = snippet
 		}
 
 		results = append(results, item)
 	}
 
-	return total, results, typeCounts
+	return total, results, typeCounts, tagCounts
 }
 
 // DeleteContent 根据短链接ID和来源删除内容
@@ -290,20 +383,44 @@ func DeleteContent(shortID string, source string) error {
 	}
 
 	// 执行删除操作
-	result = DB.Delete(&content)
-	if result.Error != nil {
-		return fmt.Errorf("删除内容失败: %v", result.Error)
+	var paths []string
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		paths, err = deleteContentRecords(tx, content)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
+	// 上传的文件在没有其他引用时删除
+	releaseUploadedFiles(paths)
+
 	return nil
 }
 
-// UpdateContent 更新内容
-func UpdateContent(content *models.Content) error {
-	result := DB.Save(content)
-	if result.Error != nil {
-		return fmt.Errorf("更新内容失败: %v", result.Error)
+// deleteContentRecords 在事务中删除内容及其历史版本、标签、合集中的引用和文件引用
+// 返回内容引用过的上传文件路径，由调用方在事务提交后释放
+func deleteContentRecords(tx *gorm.DB, content models.Content) ([]string, error) {
+	if err := tx.Delete(&content).Error; err != nil {
+		return nil, fmt.Errorf("删除内容失败: %v", err)
+	}
+	if err := deleteContentRevisions(tx, content.ID); err != nil {
+		return nil, fmt.Errorf("删除历史版本失败: %v", err)
+	}
+	if err := deleteContentTags(tx, content.ID); err != nil {
+		return nil, fmt.Errorf("删除标签关联失败: %v", err)
+	}
+	if err := deleteContentCollectionItems(tx, content.ID); err != nil {
+		return nil, fmt.Errorf("移除合集内容失败: %v", err)
 	}
+	if err := deleteContentReports(tx, content.ID); err != nil {
+		return nil, fmt.Errorf("删除举报记录失败: %v", err)
+	}
+	return deleteContentUploadReferences(tx, content.ID)
+}
 
-	return nil
+// UpdateContent 更新内容，标题、内容或公开状态发生变化时记录新版本
+func UpdateContent(content *models.Content, editor string) error {
+	return saveContentWithRevision(content, editor, "")
 }
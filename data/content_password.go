@@ -0,0 +1,127 @@
+package data
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"sharesth/models"
+)
+
+// 解锁Cookie相关常量
+const (
+	// 解锁Cookie名称前缀，完整名称为前缀加短链接ID
+	UnlockCookiePrefix = "sharesth_unlock_"
+	// 解锁后的有效期
+	UnlockExpiration = 12 * time.Hour
+	// 上传文件访问令牌的有效期，足够页面加载图片和用户点击下载
+	UploadGrantExpiration = 10 * time.Minute
+	// 上传文件访问令牌的查询参数名
+	UploadGrantParam = "grant"
+)
+
+// HashContentPassword 生成内容访问密码的哈希
+func HashContentPassword(password string) (string, error) {
+	if len(password) > MaxPasswordLength {
+		return "", fmt.Errorf("访问密码不能超过%d个字符", MaxPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("生成密码哈希失败: %v", err)
+	}
+
+	return string(hash), nil
+}
+
+// CheckContentPassword 校验内容访问密码
+func CheckContentPassword(content models.Content, password string) bool {
+	if !content.IsProtected() {
+		return true
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(content.Password), []byte(password)) == nil
+}
+
+// unlockMessage 构造解锁令牌的签名内容，包含密码哈希以便修改密码后旧令牌失效
+func unlockMessage(content models.Content, expires int64) string {
+	return fmt.Sprintf("unlock|%s|%d|%s", content.ShortID, expires, content.Password)
+}
+
+// CreateUnlockToken 为内容生成有时效的签名解锁令牌
+func CreateUnlockToken(content models.Content) (string, time.Time) {
+	expiresAt := time.Now().Add(UnlockExpiration)
+	expires := expiresAt.Unix()
+
+	return fmt.Sprintf("%d.%s", expires, Sign(unlockMessage(content, expires))), expiresAt
+}
+
+// VerifyUnlockToken 校验内容的解锁令牌
+func VerifyUnlockToken(content models.Content, token string) bool {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+
+	return VerifySignature(unlockMessage(content, expires), parts[1])
+}
+
+// IsContentUnlocked 判断请求是否已解锁指定内容，未设置密码的内容始终视为已解锁
+func IsContentUnlocked(r *http.Request, content models.Content) bool {
+	if !content.IsProtected() {
+		return true
+	}
+
+	cookie, err := r.Cookie(UnlockCookiePrefix + content.ShortID)
+	if err != nil {
+		return false
+	}
+
+	return VerifyUnlockToken(content, cookie.Value)
+}
+
+// uploadGrantMessage 构造上传文件访问令牌的签名内容
+func uploadGrantMessage(filePath string, expires int64) string {
+	return fmt.Sprintf("upload|%s|%d", UploadPath(UploadKey(filePath)), expires)
+}
+
+// GrantedUploadURL 返回附带访问令牌的上传文件地址
+// 访问次数受限的内容不能直接访问上传文件，页面在计入访问后通过该地址展示和下载文件
+func GrantedUploadURL(filePath string) string {
+	expires := time.Now().Add(UploadGrantExpiration).Unix()
+	token := fmt.Sprintf("%d.%s", expires, Sign(uploadGrantMessage(filePath, expires)))
+	return "/" + UploadPath(UploadKey(filePath)) + "?" + UploadGrantParam + "=" + token
+}
+
+// VerifyUploadGrant 校验上传文件的访问令牌
+func VerifyUploadGrant(filePath string, token string) bool {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+
+	return VerifySignature(uploadGrantMessage(filePath, expires), parts[1])
+}
+
+// FindContentsByUploadPath 查找引用了指定上传文件的内容，包括图片内容和Markdown中嵌入的图片
+func FindContentsByUploadPath(filePath string) []models.Content {
+	var contents []models.Content
+	DB.Where("id IN (?)", DB.Model(&models.UploadReference{}).Select("content_id").Where("file_path = ?", filePath)).
+		Find(&contents)
+
+	return contents
+}
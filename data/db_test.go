@@ -0,0 +1,326 @@
+package data
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+
+	"sharesth/config"
+	"sharesth/models"
+)
+
+// testDatabase 运行集成测试的数据库
+type testDatabase struct {
+	name string
+	cfg  config.DatabaseConfig
+}
+
+// testDatabases 返回运行集成测试的数据库，始终包含临时目录中的SQLite
+// 设置 SHARESTH_TEST_PG_DSN 或 SHARESTH_TEST_MYSQL_DSN 时还在对应的数据库上运行，测试会删除其中的所有表，请使用专门的测试库
+func testDatabases(t *testing.T) []testDatabase {
+	databases := []testDatabase{
+		{"sqlite", config.DatabaseConfig{Path: filepath.Join(t.TempDir(), "sharesth.db")}},
+	}
+	if dsn := os.Getenv("SHARESTH_TEST_PG_DSN"); dsn != "" {
+		databases = append(databases, testDatabase{"postgres", config.DatabaseConfig{DSN: dsn}})
+	}
+	if dsn := os.Getenv("SHARESTH_TEST_MYSQL_DSN"); dsn != "" {
+		databases = append(databases, testDatabase{"mysql", config.DatabaseConfig{DSN: dsn}})
+	}
+	return databases
+}
+
+// forEachDatabase 在每个测试数据库上运行 test，运行前数据库已清空并执行了所有迁移
+func forEachDatabase(t *testing.T, test func(t *testing.T)) {
+	for _, database := range testDatabases(t) {
+		database := database
+		t.Run(database.name, func(t *testing.T) {
+			resetTestDB(t, database.cfg)
+			if _, err := MigrateUp(); err != nil {
+				t.Fatalf("MigrateUp: %v", err)
+			}
+			if err := initSearchIndex(); err != nil {
+				t.Fatalf("initSearchIndex: %v", err)
+			}
+			test(t)
+		})
+	}
+}
+
+// resetTestDB 连接数据库并撤销所有迁移，测试结束时关闭连接
+func resetTestDB(t *testing.T, cfg config.DatabaseConfig) {
+	t.Helper()
+	if err := OpenDB(cfg, logger.Silent); err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	t.Cleanup(CloseDB)
+
+	if _, err := MigrateDown(len(migrations)); err != nil {
+		t.Fatalf("MigrateDown: %v", err)
+	}
+}
+
+// createTestContent 保存测试内容，未指定创建时间时使用当前时间
+func createTestContent(t *testing.T, content models.Content) models.Content {
+	t.Helper()
+	if content.CreateTime.IsZero() {
+		content.CreateTime = time.Now()
+	}
+	content.UpdateTime = content.CreateTime
+	if err := DB.Create(&content).Error; err != nil {
+		t.Fatalf("创建内容 %s 失败: %v", content.ShortID, err)
+	}
+	return content
+}
+
+// shortIDs 返回结果列表中的短链接ID
+func shortIDs(items []map[string]interface{}) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item["short_id"].(string))
+	}
+	return ids
+}
+
+func TestInitDB(t *testing.T) {
+	for _, database := range testDatabases(t) {
+		database := database
+		t.Run(database.name, func(t *testing.T) {
+			resetTestDB(t, database.cfg)
+			CloseDB()
+
+			cfg := database.cfg
+			if err := InitDB(cfg); !errors.Is(err, ErrSchemaBehind) {
+				t.Fatalf("未执行迁移时 InitDB 返回 %v，应为 ErrSchemaBehind", err)
+			}
+			CloseDB()
+
+			cfg.AutoMigrate = true
+			if err := InitDB(cfg); err != nil {
+				t.Fatalf("InitDB: %v", err)
+			}
+			CloseDB()
+
+			// 已是最新版本时不再执行迁移
+			cfg.AutoMigrate = false
+			if err := InitDB(cfg); err != nil {
+				t.Fatalf("再次 InitDB: %v", err)
+			}
+
+			statuses, err := FindMigrationStatus()
+			if err != nil {
+				t.Fatalf("FindMigrationStatus: %v", err)
+			}
+			if len(statuses) != len(migrations) {
+				t.Fatalf("迁移状态有 %d 项，应为 %d 项", len(statuses), len(migrations))
+			}
+			for _, status := range statuses {
+				if status.AppliedAt == nil || status.Unknown {
+					t.Errorf("迁移 %04d_%s 状态异常: %+v", status.Version, status.Name, status)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T) {
+		if err := CheckSchema(); err != nil {
+			t.Fatalf("CheckSchema: %v", err)
+		}
+		if !DB.Migrator().HasColumn(&models.Content{}, "Locked") || !DB.Migrator().HasColumn(&models.Report{}, "ReporterIP") {
+			t.Fatal("迁移后缺少 contents.locked 或 reports.reporter_ip 列")
+		}
+
+		// 撤销最近一个迁移后结构落后
+		if _, err := MigrateDown(1); err != nil {
+			t.Fatalf("MigrateDown: %v", err)
+		}
+		if err := CheckSchema(); !errors.Is(err, ErrSchemaBehind) {
+			t.Fatalf("撤销迁移后 CheckSchema 返回 %v，应为 ErrSchemaBehind", err)
+		}
+		if ran, err := MigrateUp(); err != nil || len(ran) != 1 {
+			t.Fatalf("MigrateUp 执行了 %d 个迁移: %v", len(ran), err)
+		}
+
+		// MySQL的DDL不随事务回滚，所有迁移都必须可以重复执行
+		for i := len(migrations) - 1; i > 0; i-- {
+			for j := 0; j < 2; j++ {
+				if err := migrations[i].Down(DB); err != nil {
+					t.Fatalf("第%d次撤销迁移 %04d_%s: %v", j+1, migrations[i].Version, migrations[i].Name, err)
+				}
+			}
+		}
+		for i := 1; i < len(migrations); i++ {
+			for j := 0; j < 2; j++ {
+				if err := migrations[i].Up(DB); err != nil {
+					t.Fatalf("第%d次执行迁移 %04d_%s: %v", j+1, migrations[i].Version, migrations[i].Name, err)
+				}
+			}
+		}
+
+		// 撤销全部迁移后所有表都被删除
+		if _, err := MigrateDown(len(migrations)); err != nil {
+			t.Fatalf("MigrateDown: %v", err)
+		}
+		for _, table := range []string{"contents", "reports", "tags", searchIndexTable} {
+			if DB.Migrator().HasTable(table) {
+				t.Errorf("撤销全部迁移后仍有表 %s", table)
+			}
+		}
+	})
+}
+
+func TestFindContentsBySourcePaginated(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T) {
+		tags, err := ResolveTags([]string{"go"})
+		if err != nil {
+			t.Fatalf("ResolveTags: %v", err)
+		}
+
+		base := time.Now().Add(-time.Hour)
+		for i, shortID := range []string{"alice1", "alice2", "alice3", "alice4", "alice5"} {
+			content := models.Content{ShortID: shortID, Type: "text", Data: "text " + shortID, Source: "alice", CreateTime: base.Add(time.Duration(i) * time.Minute)}
+			if i < 2 {
+				content.Tags = tags
+			}
+			createTestContent(t, content)
+		}
+		createTestContent(t, models.Content{ShortID: "alice6", Type: "code", Data: "package main", Source: "alice", CreateTime: base.Add(10 * time.Minute)})
+		createTestContent(t, models.Content{ShortID: "bob1", Type: "text", Data: "bob", Source: "bob"})
+
+		total, items, typeCounts, tagCounts := FindContentsBySourcePaginated("alice", "", "", "", 1, 2)
+		if total != 6 || strings.Join(shortIDs(items), ",") != "alice6,alice5" {
+			t.Fatalf("第1页: total=%d items=%v", total, shortIDs(items))
+		}
+		if typeCounts["text"] != 5 || typeCounts["code"] != 1 || len(typeCounts) != 2 {
+			t.Errorf("typeCounts = %v", typeCounts)
+		}
+		if tagCounts["go"] != 2 {
+			t.Errorf("tagCounts = %v", tagCounts)
+		}
+
+		if _, items, _, _ = FindContentsBySourcePaginated("alice", "", "", "", 3, 2); strings.Join(shortIDs(items), ",") != "alice2,alice1" {
+			t.Errorf("第3页: %v", shortIDs(items))
+		}
+		if total, items, _, _ = FindContentsBySourcePaginated("alice", "", "code", "", 1, 10); total != 1 || len(items) != 1 {
+			t.Errorf("类型筛选: total=%d items=%v", total, shortIDs(items))
+		}
+		if total, items, _, _ = FindContentsBySourcePaginated("alice", "", "", "GO", 1, 10); total != 2 || strings.Join(shortIDs(items), ",") != "alice2,alice1" {
+			t.Errorf("标签筛选: total=%d items=%v", total, shortIDs(items))
+		}
+		if total, _, _, _ = FindContentsBySourcePaginated("carol", "", "", "", 1, 10); total != 0 {
+			t.Errorf("没有内容的来源: total=%d", total)
+		}
+	})
+}
+
+func TestFindPublicContentsPaginated(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		base := time.Now().Add(-time.Hour)
+		createTestContent(t, models.Content{ShortID: "public1", Type: "text", Data: "first", Source: "alice", IsPublic: true, CreateTime: base})
+		createTestContent(t, models.Content{ShortID: "public2", Type: "markdown", Data: "# second", Source: "bob", IsPublic: true, CreateTime: base.Add(time.Minute)})
+		createTestContent(t, models.Content{ShortID: "limited", Type: "text", Data: "burn after reading", Source: "bob", IsPublic: true, MaxViews: 1, CreateTime: base.Add(2 * time.Minute)})
+		createTestContent(t, models.Content{ShortID: "private", Type: "text", Data: "private", Source: "alice"})
+		createTestContent(t, models.Content{ShortID: "expired", Type: "text", Data: "expired", Source: "alice", IsPublic: true, ExpiresAt: &past})
+		createTestContent(t, models.Content{ShortID: "usedup", Type: "text", Data: "used up", Source: "alice", IsPublic: true, MaxViews: 1, ViewCount: 1})
+
+		total, items, typeCounts, _ := FindPublicContentsPaginated("", "all", "", 1, 10)
+		if total != 3 || strings.Join(shortIDs(items), ",") != "limited,public2,public1" {
+			t.Fatalf("total=%d items=%v", total, shortIDs(items))
+		}
+		if typeCounts["text"] != 2 || typeCounts["markdown"] != 1 {
+			t.Errorf("typeCounts = %v", typeCounts)
+		}
+
+		// 限制访问次数的内容不展示预览，文本内容展示摘要
+		for _, item := range items {
+			_, hasSummary := item["summary"]
+			if hasSummary != (item["short_id"] != "limited") {
+				t.Errorf("%s 的摘要: %v", item["short_id"], item["summary"])
+			}
+		}
+
+		if total, items, _, _ = FindPublicContentsPaginated("", "markdown", "", 1, 10); total != 1 || items[0]["short_id"] != "public2" {
+			t.Errorf("类型筛选: total=%d items=%v", total, shortIDs(items))
+		}
+		if _, items, _, _ = FindPublicContentsPaginated("", "", "", 2, 2); strings.Join(shortIDs(items), ",") != "public1" {
+			t.Errorf("第2页: %v", shortIDs(items))
+		}
+	})
+}
+
+func TestSearchContents(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T) {
+		base := time.Now().Add(-time.Hour)
+		createTestContent(t, models.Content{ShortID: "notes", Type: "text", Title: "Go 并发笔记", Data: "channels and goroutines", Source: "alice", IsPublic: true, CreateTime: base})
+		createTestContent(t, models.Content{ShortID: "shopping", Type: "markdown", Title: "shopping", Data: "buy zebra food", Source: "alice", IsPublic: true, CreateTime: base.Add(time.Minute)})
+		createTestContent(t, models.Content{ShortID: "locked", Type: "text", Title: "secret", Data: "zebra password", Password: "hash", Source: "alice", IsPublic: true, CreateTime: base.Add(2 * time.Minute)})
+		createTestContent(t, models.Content{ShortID: "limited", Type: "text", Title: "burn", Data: "zebra once", MaxViews: 1, Source: "alice", IsPublic: true, CreateTime: base.Add(2 * time.Minute)})
+		createTestContent(t, models.Content{ShortID: "picture", Type: "image", Title: "Zebra picture", Data: "uploads/zebra.png", Source: "alice", IsPublic: true, CreateTime: base.Add(3 * time.Minute)})
+
+		search := func(query string) string {
+			var contents []models.Content
+			if err := searchContents(DB.Model(&models.Content{}), query).Find(&contents).Error; err != nil {
+				t.Fatalf("搜索 %q 失败: %v", query, err)
+			}
+			ids := make([]string, 0, len(contents))
+			for _, content := range contents {
+				ids = append(ids, content.ShortID)
+			}
+			return strings.Join(ids, ",")
+		}
+
+		// 标题模糊匹配不依赖全文索引，且不区分大小写
+		if got := search("并发"); got != "notes" {
+			t.Errorf("搜索 并发: %s", got)
+		}
+		if got := search("ZEBRA PIC"); got != "picture" {
+			t.Errorf("搜索 ZEBRA PIC: %s", got)
+		}
+
+		if searchIndex == searchIndexNone {
+			t.Logf("%s 没有全文索引，只测试标题匹配", DB.Dialector.Name())
+			if got := search("zebra"); got != "picture" {
+				t.Errorf("搜索 zebra: %s", got)
+			}
+			return
+		}
+
+		// 标题匹配排在正文匹配之前，设置了访问密码或访问次数限制的内容不索引正文
+		if got := search("zebra"); got != "picture,shopping" {
+			t.Errorf("搜索 zebra: %s", got)
+		}
+		if got := search("gorout*"); got != "notes" {
+			t.Errorf("搜索 gorout*: %s", got)
+		}
+		if got := search(`"and goroutines"`); got != "notes" {
+			t.Errorf("搜索短语: %s", got)
+		}
+		if got := search(`"goroutines and"`); got != "" {
+			t.Errorf("搜索不存在的短语: %s", got)
+		}
+
+		_, items, _, _ := FindPublicContentsPaginated("zebra", "", "", 1, 10)
+		for _, item := range items {
+			snippet, _ := item["snippet"].(string)
+			if item["short_id"] == "shopping" && !strings.Contains(snippet, "<mark>zebra</mark>") {
+				t.Errorf("正文匹配的摘要: %q", snippet)
+			}
+		}
+
+		// 设置访问次数限制后正文从索引中移除
+		if err := DB.Model(&models.Content{}).Where("short_id = ?", "shopping").Update("max_views", 3).Error; err != nil {
+			t.Fatalf("设置访问次数限制失败: %v", err)
+		}
+		if got := search("zebra"); got != "picture" {
+			t.Errorf("设置访问次数限制后搜索 zebra: %s", got)
+		}
+	})
+}
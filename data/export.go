@@ -0,0 +1,257 @@
+package data
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"path"
+	"time"
+
+	"sharesth/models"
+	"sharesth/utils"
+)
+
+// 导出归档相关常量
+const (
+	// 导出清单格式版本
+	ExportManifestVersion = 1
+	// 清单文件在归档中的路径
+	ExportManifestName = "manifest.json"
+	// 离线浏览用的索引页面
+	exportIndexName = "index.html"
+	// 内容文件和上传文件在归档中的目录
+	exportContentsDir = "contents"
+	exportUploadsDir  = "uploads"
+)
+
+// ExportManifest 导出归档中的清单
+type ExportManifest struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Contents   []ExportedContent `json:"contents"`
+}
+
+// ExportedContent 清单中的单个内容，Data 保留数据库中的原始数据，Path 和 Uploads 为归档中的相对路径
+// 访问密码不会导出
+type ExportedContent struct {
+	ShortID    string     `json:"short_id"`
+	Type       string     `json:"type"`
+	Title      string     `json:"title"`
+	Data       string     `json:"data"`
+	Language   string     `json:"language,omitempty"`
+	FileName   string     `json:"file_name,omitempty"`
+	MimeType   string     `json:"mime_type,omitempty"`
+	FileSize   int64      `json:"file_size,omitempty"`
+	IsPublic   bool       `json:"is_public"`
+	CreateTime time.Time  `json:"create_time"`
+	UpdateTime time.Time  `json:"update_time"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	MaxViews   int        `json:"max_views,omitempty"`
+	Protected  bool       `json:"protected,omitempty"`
+	Tags       []string   `json:"tags"`
+	Path       string     `json:"path"`
+	Uploads    []string   `json:"uploads,omitempty"`
+}
+
+// exportIndexTemplate 归档中的索引页面，按清单顺序链接每个内容文件
+var exportIndexTemplate = template.Must(template.New(exportIndexName).Parse(`<!DOCTYPE html>
+<html lang="zh">
+<head>
+<meta charset="UTF-8">
+<title>ShareSTH 导出 - {{.ExportedAt.Format "2006-01-02 15:04:05"}}</title>
+</head>
+<body>
+<h1>ShareSTH 导出</h1>
+<p>导出时间: {{.ExportedAt.Format "2006-01-02 15:04:05"}}，共 {{len .Contents}} 项内容</p>
+<ul>
+{{range .Contents}}<li><a href="{{.Path}}">{{if .Title}}{{.Title}}{{else}}{{.ShortID}}{{end}}</a> ({{.Type}}, {{.CreateTime.Format "2006-01-02 15:04"}}){{if .Tags}} {{range .Tags}}#{{.}} {{end}}{{end}}</li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// ArchiveUploadPath 将上传文件路径转换为归档中的相对路径
+func ArchiveUploadPath(filePath string) string {
+	return path.Join(exportUploadsDir, UploadKey(filePath))
+}
+
+// archiveContentPath 返回内容在归档中的文件路径：文本类内容写入单独的文件，图片和文件指向上传文件
+func archiveContentPath(content models.Content) string {
+	switch content.Type {
+	case "markdown":
+		return path.Join(exportContentsDir, content.ShortID+".md")
+	case "code":
+		return path.Join(exportContentsDir, content.ShortID+utils.LanguageExtension(content.Language))
+	case "image", "file":
+		return ArchiveUploadPath(content.Data)
+	default:
+		return path.Join(exportContentsDir, content.ShortID+".txt")
+	}
+}
+
+// rewriteUploadLinks 将Markdown中引用上传文件的绝对路径替换为 prefix 开头的相对路径
+// 只替换站内链接，完整URL中的 /uploads/ 保持不变
+func rewriteUploadLinks(markdown string, prefix string) string {
+	matches := uploadLinkPattern.FindAllStringIndex(markdown, -1)
+	if len(matches) == 0 {
+		return markdown
+	}
+
+	var rewritten []byte
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if start > 0 && !isLinkBoundary(markdown[start-1]) {
+			continue
+		}
+		rewritten = append(rewritten, markdown[last:start]...)
+		rewritten = append(rewritten, prefix...)
+		rewritten = append(rewritten, markdown[start+1:end]...)
+		last = end
+	}
+	rewritten = append(rewritten, markdown[last:]...)
+
+	return string(rewritten)
+}
+
+// isLinkBoundary 判断字符是否可以出现在站内链接之前
+func isLinkBoundary(b byte) bool {
+	switch b {
+	case '(', '"', '\'', '=', '<', ' ', '\t', '\n':
+		return true
+	}
+	return false
+}
+
+// WriteExportArchive 将指定来源的所有内容写入zip归档
+// 归档包含清单、索引页面、文本类内容的文件以及内容引用的上传文件，Markdown中的图片链接改写为归档内的相对路径
+func WriteExportArchive(w io.Writer, source string) error {
+	var contents []models.Content
+	if err := DB.Preload("Tags").Where("source = ?", source).Order("create_time").Find(&contents).Error; err != nil {
+		return fmt.Errorf("查询内容失败: %v", err)
+	}
+
+	manifest := ExportManifest{
+		Version:    ExportManifestVersion,
+		ExportedAt: time.Now(),
+		Contents:   make([]ExportedContent, 0, len(contents)),
+	}
+
+	// 上传文件按MD5去重，多个内容可能引用同一个文件，只写入一次
+	uploads := make([]string, 0)
+	seenUploads := make(map[string]bool)
+
+	for _, content := range contents {
+		exported := ExportedContent{
+			ShortID:    content.ShortID,
+			Type:       content.Type,
+			Title:      content.Title,
+			Data:       content.Data,
+			Language:   content.Language,
+			FileName:   content.FileName,
+			MimeType:   content.MimeType,
+			FileSize:   content.FileSize,
+			IsPublic:   content.IsPublic,
+			CreateTime: content.CreateTime,
+			UpdateTime: content.UpdateTime,
+			ExpiresAt:  content.ExpiresAt,
+			MaxViews:   content.MaxViews,
+			Protected:  content.IsProtected(),
+			Tags:       content.TagNames(),
+			Path:       archiveContentPath(content),
+		}
+
+		for _, filePath := range ExtractUploadPaths(content.Type, content.Data) {
+			exported.Uploads = append(exported.Uploads, ArchiveUploadPath(filePath))
+			if !seenUploads[filePath] {
+				seenUploads[filePath] = true
+				uploads = append(uploads, filePath)
+			}
+		}
+
+		manifest.Contents = append(manifest.Contents, exported)
+	}
+
+	archive := zip.NewWriter(w)
+
+	// 清单
+	manifestWriter, err := archive.CreateHeader(&zip.FileHeader{Name: ExportManifestName, Method: zip.Deflate, Modified: manifest.ExportedAt})
+	if err != nil {
+		return fmt.Errorf("写入导出清单失败: %v", err)
+	}
+	encoder := json.NewEncoder(manifestWriter)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return fmt.Errorf("写入导出清单失败: %v", err)
+	}
+
+	// 索引页面
+	indexWriter, err := archive.CreateHeader(&zip.FileHeader{Name: exportIndexName, Method: zip.Deflate, Modified: manifest.ExportedAt})
+	if err != nil {
+		return fmt.Errorf("写入索引页面失败: %v", err)
+	}
+	if err := exportIndexTemplate.Execute(indexWriter, manifest); err != nil {
+		return fmt.Errorf("写入索引页面失败: %v", err)
+	}
+
+	// 文本、Markdown和代码内容
+	for i, content := range contents {
+		if !content.IsTextual() {
+			continue
+		}
+
+		data := content.Data
+		if content.Type == "markdown" {
+			// 内容文件位于 contents/ 目录下，上传文件位于同级的 uploads/ 目录
+			data = rewriteUploadLinks(data, "../")
+		}
+
+		header := &zip.FileHeader{Name: manifest.Contents[i].Path, Method: zip.Deflate, Modified: content.UpdateTime}
+		entry, err := archive.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("写入内容 %s 失败: %v", content.ShortID, err)
+		}
+		if _, err := io.WriteString(entry, data); err != nil {
+			return fmt.Errorf("写入内容 %s 失败: %v", content.ShortID, err)
+		}
+	}
+
+	// 上传文件
+	for _, filePath := range uploads {
+		if err := writeArchiveUpload(archive, filePath); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("写入导出归档失败: %v", err)
+	}
+
+	return nil
+}
+
+// writeArchiveUpload 将上传文件写入归档，存储中已不存在的文件只记录日志
+func writeArchiveUpload(archive *zip.Writer, filePath string) error {
+	reader, info, err := Store.Get(context.Background(), UploadKey(filePath))
+	if err != nil {
+		log.Printf("导出时读取上传文件失败: %s, %v", filePath, err)
+		return nil
+	}
+	defer reader.Close()
+
+	// 图片等文件通常已经压缩过，直接存储
+	header := &zip.FileHeader{Name: ArchiveUploadPath(filePath), Method: zip.Store, Modified: info.ModTime}
+	entry, err := archive.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("写入上传文件 %s 失败: %v", filePath, err)
+	}
+	if _, err := io.Copy(entry, reader); err != nil {
+		return fmt.Errorf("写入上传文件 %s 失败: %v", filePath, err)
+	}
+
+	return nil
+}
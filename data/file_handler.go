@@ -1,33 +1,63 @@
 package data
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"log"
+	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"sharesth/models"
 	"sharesth/utils"
 )
 
+// UploadedFile 保存后的上传文件信息
+type UploadedFile struct {
+	Path     string // 文件路径，如 uploads/xxx.pdf
+	MimeType string // 根据文件内容识别的MIME类型
+	Size     int64  // 文件大小（字节）
+}
+
+// unsafeFilenameChars 文件名中需要替换的字符，保证生成的路径可以直接用于URL
+var unsafeFilenameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// detectMimeType 根据文件开头的内容识别MIME类型，无法识别时参考扩展名
+func detectMimeType(head []byte, fileExt string) string {
+	mimeType := http.DetectContentType(head)
+	if mimeType == "application/octet-stream" {
+		if byExt := mime.TypeByExtension(fileExt); byExt != "" {
+			mimeType = byExt
+		}
+	}
+	return mimeType
+}
+
 // SaveUploadedImage 保存上传的图片并返回唯一的文件路径
 // 如果发现相同内容的文件已存在，则直接返回已存在的文件路径
 func SaveUploadedImage(file io.Reader, originalFilename string) (string, error) {
-	// 确保上传目录存在
-	if _, err := os.Stat(utils.UploadsDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(utils.UploadsDir, 0755); err != nil {
-			return "", fmt.Errorf("创建上传目录失败: %v", err)
-		}
+	uploaded, err := SaveUploadedFile(file, originalFilename)
+	if err != nil {
+		return "", err
 	}
 
+	return uploaded.Path, nil
+}
+
+// SaveUploadedFile 保存任意类型的上传文件并返回文件信息
+// 文件按内容MD5去重，相同内容的文件只保存一份
+func SaveUploadedFile(file io.Reader, originalFilename string) (UploadedFile, error) {
 	// 计算文件内容的MD5
 	tempFile, err := os.CreateTemp("", "upload-*")
 	if err != nil {
-		return "", fmt.Errorf("创建临时文件失败: %v", err)
+		return UploadedFile{}, fmt.Errorf("创建临时文件失败: %v", err)
 	}
 	defer os.Remove(tempFile.Name())
 	defer tempFile.Close()
@@ -36,25 +66,40 @@ func SaveUploadedImage(file io.Reader, originalFilename string) (string, error)
 	hash := md5.New()
 	multiWriter := io.MultiWriter(tempFile, hash)
 
-	if _, err := io.Copy(multiWriter, file); err != nil {
-		return "", fmt.Errorf("复制文件内容失败: %v", err)
+	size, err := io.Copy(multiWriter, file)
+	if err != nil {
+		return UploadedFile{}, fmt.Errorf("复制文件内容失败: %v", err)
 	}
 
+	// 根据文件开头的内容识别MIME类型
+	head := make([]byte, 512)
+	n, _ := tempFile.ReadAt(head, 0)
+
 	// 获取文件MD5和扩展名
 	contentMD5 := hex.EncodeToString(hash.Sum(nil))
-	fileExt := filepath.Ext(originalFilename)
-	if fileExt == "" {
-		// 如果没有扩展名，默认为.jpg
-		fileExt = ".jpg"
+	fileExt := unsafeFilenameChars.ReplaceAllString(strings.ToLower(filepath.Ext(originalFilename)), "")
+	mimeType := detectMimeType(head[:n], fileExt)
+	if fileExt == "" || fileExt == "." {
+		// 如果没有扩展名，图片默认为.jpg，其他文件默认为.bin
+		if strings.HasPrefix(mimeType, "image/") {
+			fileExt = ".jpg"
+		} else {
+			fileExt = ".bin"
+		}
 	}
 
 	// 检查是否已存在相同MD5的文件
 	existingFile, found := FindFileMD5(contentMD5)
 	if found {
 		// 验证文件是否真的存在
-		if _, err := os.Stat(existingFile); err == nil {
+		if _, err := Store.Stat(context.Background(), UploadKey(existingFile)); err == nil {
+			// 刷新上传时间，使已无引用的文件重新进入回收保护期，
+			// 否则编辑器中重复上传、所在内容尚未保存的图片可能被立即回收
+			if err := TouchFileMD5(existingFile); err != nil {
+				return UploadedFile{}, fmt.Errorf("刷新重复文件失败: %v", err)
+			}
 			log.Printf("找到重复文件: %s (MD5: %s)", existingFile, contentMD5)
-			return existingFile, nil
+			return UploadedFile{Path: existingFile, MimeType: mimeType, Size: size}, nil
 		}
 		// 如果文件不存在，从索引中删除并继续处理
 		DeleteFileMD5(contentMD5)
@@ -68,24 +113,18 @@ func SaveUploadedImage(file io.Reader, originalFilename string) (string, error)
 	if len(baseFilename) > 20 {
 		baseFilename = baseFilename[:20]
 	}
-	// 移除扩展名并转换为小写
+	// 移除扩展名并转换为小写，替换不适合出现在URL中的字符
 	baseFilename = strings.ToLower(strings.TrimSuffix(baseFilename, filepath.Ext(baseFilename)))
+	baseFilename = unsafeFilenameChars.ReplaceAllString(baseFilename, "_")
 	// 生成最终的文件名
 	filename := fmt.Sprintf("%d_%s_%s%s", timestamp, contentMD5[:8], baseFilename, fileExt)
 	// 生成文件路径
-	filePath := filepath.Join(utils.UploadsDir, filename)
-
-	// 将临时文件内容复制到最终文件
-	tempFile.Seek(0, 0)
-	finalFile, err := os.Create(filePath)
-	if err != nil {
-		return "", fmt.Errorf("创建最终文件失败: %v", err)
-	}
-	defer finalFile.Close()
+	filePath := filepath.ToSlash(filepath.Join(utils.UploadsDir, filename))
 
-	if _, err := io.Copy(finalFile, tempFile); err != nil {
-		os.Remove(filePath) // 失败时清理
-		return "", fmt.Errorf("写入最终文件失败: %v", err)
+	// 将临时文件内容写入存储后端
+	tempFile.Seek(0, io.SeekStart)
+	if err := Store.Put(context.Background(), UploadKey(filePath), tempFile, size, mimeType); err != nil {
+		return UploadedFile{}, fmt.Errorf("写入最终文件失败: %v", err)
 	}
 
 	// 记录MD5与文件路径的对应关系
@@ -94,5 +133,28 @@ func SaveUploadedImage(file io.Reader, originalFilename string) (string, error)
 	}
 
 	log.Printf("保存新文件: %s (MD5: %s)", filePath, contentMD5)
-	return filePath, nil
+	return UploadedFile{Path: filePath, MimeType: mimeType, Size: size}, nil
+}
+
+// ReleaseUploadedFile 在没有其他内容引用时删除上传的文件及其MD5索引
+// 处于回收保护期内的文件可能刚被重复上传、所在内容尚未保存，留给定期回收处理
+func ReleaseUploadedFile(filePath string) {
+	// 由于上传文件按MD5去重，同一文件可能被多个内容引用
+	if CountUploadReferences(filePath) > 0 {
+		return
+	}
+
+	ctx := context.Background()
+	if recentlyUploaded(ctx, filePath) {
+		log.Printf("上传文件处于回收保护期，暂不删除: %s", filePath)
+		return
+	}
+
+	if err := Store.Delete(ctx, UploadKey(filePath)); err != nil {
+		log.Printf("删除上传文件失败: %v", err)
+		return
+	}
+	DB.Where("file_path = ?", filePath).Delete(&models.FileMD5{})
+
+	log.Printf("删除无引用的上传文件: %s", filePath)
 }
@@ -1,6 +1,8 @@
 package data
 
 import (
+	"time"
+
 	"sharesth/models"
 )
 
@@ -17,14 +19,21 @@ func FindFileMD5(md5Hash string) (string, bool) {
 
 // SaveFileMD5 保存MD5哈希与文件路径的映射
 func SaveFileMD5(md5Hash string, filePath string) error {
+	now := time.Now()
 	fileMD5 := models.FileMD5{
-		MD5Hash:  md5Hash,
-		FilePath: filePath,
+		MD5Hash:    md5Hash,
+		FilePath:   filePath,
+		UploadedAt: &now,
 	}
 
 	return DB.Create(&fileMD5).Error
 }
 
+// TouchFileMD5 刷新文件的上传时间，使重复上传的文件重新进入回收保护期
+func TouchFileMD5(filePath string) error {
+	return DB.Model(&models.FileMD5{}).Where("file_path = ?", filePath).Update("uploaded_at", time.Now()).Error
+}
+
 // DeleteFileMD5 删除指定MD5哈希的记录
 func DeleteFileMD5(md5Hash string) error {
 	return DB.Where("md5_hash = ?", md5Hash).Delete(&models.FileMD5{}).Error
@@ -0,0 +1,68 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+// 健康检查中每项依赖的超时时间
+const healthCheckTimeout = 2 * time.Second
+
+// ComponentHealth 单个依赖的健康状态
+type ComponentHealth struct {
+	Backend string `json:"backend,omitempty"` // 使用的后端
+	Status  string `json:"status"`            // "ok" 或 "error"
+	Error   string `json:"error,omitempty"`   // 不可用时的错误信息
+}
+
+// HealthReport 服务的健康状态
+type HealthReport struct {
+	Status    string          `json:"status"` // 数据库可用时为 "ok"，否则为 "error"；缓存不可用不影响服务，只降级为 "degraded"
+	Database  ComponentHealth `json:"database"`
+	Cache     ComponentHealth `json:"cache"`
+	RateLimit ComponentHealth `json:"rate_limit"`
+}
+
+// CheckHealth 检查数据库和缓存是否可用，并报告当前使用的缓存和限流后端
+func CheckHealth(ctx context.Context) HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	report := HealthReport{
+		Database:  ComponentHealth{Backend: DB.Dialector.Name()},
+		Cache:     ComponentHealth{Backend: UserIDCache.Name()},
+		RateLimit: ComponentHealth{Backend: RateLimitBackend(), Status: "ok"},
+	}
+
+	report.Database.setError(pingDB(ctx))
+	report.Cache.setError(UserIDCache.Ping(ctx))
+
+	switch {
+	case report.Database.Status != "ok":
+		report.Status = "error"
+	case report.Cache.Status != "ok":
+		report.Status = "degraded"
+	default:
+		report.Status = "ok"
+	}
+	return report
+}
+
+// setError 根据检查结果设置状态
+func (h *ComponentHealth) setError(err error) {
+	if err != nil {
+		h.Status = "error"
+		h.Error = err.Error()
+		return
+	}
+	h.Status = "ok"
+}
+
+// pingDB 检查数据库连接
+func pingDB(ctx context.Context) error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
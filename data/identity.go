@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
@@ -10,6 +11,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"sharesth/models"
 )
 
 // ID长度相关常量
@@ -61,28 +64,110 @@ func LoadAllocatedUserIDs() {
 		allocatedUserIDs[id] = true
 	}
 
+	// 加载账户认领的ID（包括不再与任何浏览器关联的ID）
+	for _, id := range GetAllAccountUserIDs() {
+		allocatedUserIDs[id] = true
+	}
+
 	log.Printf("内存中已加载 %d 个已分配的用户ID", len(allocatedUserIDs))
 }
 
-// GetClientIdentifier 获取客户端标识 - 基于用户请求的稳定特征生成唯一标识符
+// ReleaseUserID 从内存中移除不再使用的用户ID
+func ReleaseUserID(userID string) {
+	userIDMutex.Lock()
+	defer userIDMutex.Unlock()
+
+	delete(allocatedUserIDs, userID)
+}
+
+// requestUserKey 请求上下文中保存已认证账户的键
+type requestUserKey struct{}
+
+// WithRequestUser 将已认证的账户保存到请求上下文，避免同一请求重复查询
+func WithRequestUser(r *http.Request, user models.User) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), requestUserKey{}, user))
+}
+
+// GetRequestUser 获取请求对应的账户，依次检查请求上下文、API令牌和会话Cookie
+func GetRequestUser(r *http.Request) (models.User, bool) {
+	if user, found := r.Context().Value(requestUserKey{}).(models.User); found {
+		return user, true
+	}
+
+	if user, found := GetAPITokenUser(r); found {
+		return user, true
+	}
+
+	return GetSessionUser(r)
+}
+
+// GetClientIdentifier 获取客户端标识 - 已认证时使用账户的用户ID，否则基于用户请求的稳定特征生成唯一标识符
 func GetClientIdentifier(r *http.Request) string {
+	// 通过API令牌或会话认证的用户直接使用账户认领的用户ID
+	if user, found := GetRequestUser(r); found {
+		return user.UserID
+	}
+
+	return GetFingerprintIdentifier(r)
+}
+
+// GetFingerprintIdentifier 基于浏览器指纹获取匿名用户标识
+func GetFingerprintIdentifier(r *http.Request) string {
 	// 第一步：提取浏览器特征并生成哈希
 	browserHash, browserInfo := extractBrowserFingerprint(r)
 
-	// 第二步：先从Redis缓存中查找
-	if userID, found := GetUserIDFromRedis(browserHash); found {
-		log.Printf("从Redis缓存中找到用户ID: %s", userID)
-		return userID
+	// 第二步：先从缓存中查找
+	userID, found := GetCachedUserID(browserHash)
+	if found {
+		log.Printf("从缓存中找到用户ID: %s", userID)
+	} else if userID, found = FindUserIDByBrowserHash(browserHash); found {
+		// 第三步：如果缓存中没有，查询数据库
+		log.Printf("从数据库中找到用户ID: %s", userID)
 	}
 
-	// 第三步：如果Redis中没有，查询数据库
-	if userID, found := FindUserIDByBrowserHash(browserHash); found {
-		log.Printf("从数据库中找到用户ID: %s", userID)
-		return userID
+	// 第四步：缓存和数据库中都没有，生成新的用户ID
+	if !found {
+		return generateAndSaveUserID(browserHash, browserInfo)
+	}
+
+	// 已被账户认领的ID只能通过登录使用，匿名访问者需要重新分配
+	if IsUserIDClaimed(userID) {
+		return reassignBrowserUserID(browserHash, browserInfo)
+	}
+
+	return userID
+}
+
+// reassignBrowserUserID 解除浏览器与原用户ID的绑定，并为其分配新的用户ID
+func reassignBrowserUserID(browserHash string, browserInfo string) string {
+	if err := DeleteUserFingerprint(browserHash); err != nil {
+		log.Printf("删除浏览器指纹记录失败: %v", err)
 	}
 
-	// 第四步：Redis和数据库中都没有，生成新的用户ID
-	return generateAndSaveUserID(browserHash, browserInfo)
+	userID := generateAndSaveUserID(browserHash, browserInfo)
+	log.Printf("原用户ID已被账户认领，为浏览器重新分配用户ID: %s", userID)
+
+	return userID
+}
+
+// AllocateUserID 分配一个不与浏览器指纹关联的新用户ID，供注册账户使用
+func AllocateUserID() string {
+	userIDMutex.Lock()
+	defer userIDMutex.Unlock()
+
+	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	for length := DefaultUserIDLength; ; length++ {
+		for retry := 0; retry < MaxRetryAtSameLength; retry++ {
+			// 重试次数从1开始，确保使用真随机而不是指纹种子
+			userID := generateRandomID(length, chars, retry+1, "")
+			if _, exists := allocatedUserIDs[userID]; !exists {
+				allocatedUserIDs[userID] = true
+				log.Printf("为账户分配新用户ID: %s", userID)
+				return userID
+			}
+		}
+	}
 }
 
 // extractBrowserFingerprint 提取浏览器特征并生成指纹哈希
@@ -0,0 +1,563 @@
+package data
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"sharesth/models"
+	"sharesth/utils"
+)
+
+// 归档中最多导入的条目数
+const MaxImportEntries = 1000
+
+// 导入结果状态
+const (
+	// 已导入，使用原短链接ID或新生成的ID
+	ImportStatusImported = "imported"
+	// 已导入，但原短链接ID已被占用，使用了新的ID
+	ImportStatusRenamed = "renamed"
+	// 未导入，如相同内容已存在或已过期
+	ImportStatusSkipped = "skipped"
+	// 导入失败
+	ImportStatusFailed = "failed"
+)
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	// 原短链接ID未被占用时继续使用，仅对带清单的归档有效
+	PreserveIDs bool
+	// 没有清单时导入内容的公开状态
+	IsPublic bool
+}
+
+// ImportResult 归档中单个条目的导入结果
+type ImportResult struct {
+	Name    string `json:"name"`               // 清单中的原短链接ID，或没有清单时归档中的文件路径
+	ShortID string `json:"short_id,omitempty"` // 导入后的短链接ID
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// errImportFileTooLarge 归档中的文件超过上传大小限制
+var errImportFileTooLarge = fmt.Errorf("文件大小不能超过 %s", utils.FormatFileSize(utils.MaxUploadFileSize))
+
+// relativeLinkPattern 匹配Markdown中的图片、链接地址和 <img> 标签的 src 属性
+var relativeLinkPattern = regexp.MustCompile(`(\]\(\s*<?|<img\s[^>]*?src=["'])([^)\s"'>]+)`)
+
+// textFileTypes 没有清单时按扩展名识别的文本类内容
+var textFileTypes = map[string]string{
+	".md":       "markdown",
+	".markdown": "markdown",
+	".txt":      "text",
+	".text":     "text",
+	".log":      "text",
+}
+
+// importArchive 正在导入的zip归档
+type importArchive struct {
+	files    map[string]*zip.File    // 规范化后的路径到归档条目
+	uploaded map[string]UploadedFile // 已保存的归档文件，同一文件只保存一次
+	source   string
+	opts     ImportOptions
+}
+
+// ImportArchive 将zip归档中的内容导入到指定来源下
+// 归档包含导出清单时按清单恢复内容的标题、时间、公开状态和标签；否则将Markdown、文本、代码、图片和其他文件分别导入为对应类型的内容
+func ImportArchive(r io.ReaderAt, size int64, source string, opts ImportOptions) ([]ImportResult, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("读取zip归档失败: %v", err)
+	}
+	if len(reader.File) > MaxImportEntries {
+		return nil, fmt.Errorf("归档中的文件过多，最多%d个", MaxImportEntries)
+	}
+
+	archive := &importArchive{
+		files:    make(map[string]*zip.File, len(reader.File)),
+		uploaded: make(map[string]UploadedFile),
+		source:   source,
+		opts:     opts,
+	}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		archive.files[cleanArchivePath(file.Name)] = file
+	}
+
+	if _, ok := archive.files[ExportManifestName]; ok {
+		return archive.importManifest()
+	}
+	return archive.importFiles(), nil
+}
+
+// cleanArchivePath 规范化归档中的路径，去掉开头的 / 和 ../
+func cleanArchivePath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, `\`, "/")), "/")
+}
+
+// open 打开归档中的文件，读取超过上传大小限制时返回错误
+func (a *importArchive) open(name string) (io.ReadCloser, error) {
+	file, ok := a.files[name]
+	if !ok {
+		return nil, fmt.Errorf("归档中缺少文件 %s", name)
+	}
+	if file.UncompressedSize64 > utils.MaxUploadFileSize {
+		return nil, errImportFileTooLarge
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("读取归档文件 %s 失败: %v", name, err)
+	}
+	return &sizeLimitedReader{ReadCloser: rc, remaining: utils.MaxUploadFileSize}, nil
+}
+
+// read 读取归档中文件的全部内容
+func (a *importArchive) read(name string) ([]byte, error) {
+	rc, err := a.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+// upload 将归档中的文件保存为上传文件，与普通上传一样按MD5去重
+func (a *importArchive) upload(name string) (UploadedFile, error) {
+	if uploaded, ok := a.uploaded[name]; ok {
+		return uploaded, nil
+	}
+
+	rc, err := a.open(name)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+	defer rc.Close()
+
+	uploaded, err := SaveUploadedFile(rc, path.Base(name))
+	if err != nil {
+		return UploadedFile{}, err
+	}
+	a.uploaded[name] = uploaded
+
+	return uploaded, nil
+}
+
+// importManifest 按导出清单导入内容
+func (a *importArchive) importManifest() ([]ImportResult, error) {
+	data, err := a.read(ExportManifestName)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest ExportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("解析导出清单失败: %v", err)
+	}
+	if manifest.Version > ExportManifestVersion {
+		return nil, fmt.Errorf("不支持的导出清单版本: %d", manifest.Version)
+	}
+	if len(manifest.Contents) > MaxImportEntries {
+		return nil, fmt.Errorf("清单中的内容过多，最多%d个", MaxImportEntries)
+	}
+
+	results := make([]ImportResult, 0, len(manifest.Contents))
+	for _, item := range manifest.Contents {
+		result := ImportResult{Name: item.ShortID}
+
+		content, notes, err := a.contentFromManifest(item)
+		if err != nil {
+			result.Status = ImportStatusFailed
+			result.Message = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		if content.IsExpired() {
+			result.Status = ImportStatusSkipped
+			result.Message = "内容已过期"
+			results = append(results, result)
+			continue
+		}
+
+		result = a.save(result, content, item.ShortID)
+		if result.Status != ImportStatusFailed && result.Status != ImportStatusSkipped {
+			notes = append([]string{result.Message}, notes...)
+			result.Message = strings.Join(utils.FilterEmpty(notes), "；")
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// contentFromManifest 根据清单条目构造内容，并保存其引用的上传文件，notes 为需要提示用户的信息
+func (a *importArchive) contentFromManifest(item ExportedContent) (models.Content, []string, error) {
+	var notes []string
+
+	content := models.Content{
+		Type:       item.Type,
+		Source:     a.source,
+		Title:      item.Title,
+		IsPublic:   item.IsPublic,
+		CreateTime: item.CreateTime,
+		UpdateTime: item.UpdateTime,
+		ExpiresAt:  item.ExpiresAt,
+		MaxViews:   item.MaxViews,
+	}
+	if content.CreateTime.IsZero() {
+		content.CreateTime = time.Now()
+	}
+	if content.UpdateTime.IsZero() {
+		content.UpdateTime = content.CreateTime
+	}
+
+	// 访问密码不会导出，原本受保护的内容导入为不公开
+	if item.Protected {
+		content.IsPublic = false
+		notes = append(notes, "原内容设置了访问密码，已导入为不公开内容")
+	}
+
+	switch item.Type {
+	case "text", "markdown", "code":
+		content.Data = item.Data
+		if item.Type == "markdown" {
+			data, missing := a.restoreUploadLinks(item.Data)
+			content.Data = data
+			if len(missing) > 0 {
+				notes = append(notes, "归档中缺少引用的文件: "+strings.Join(missing, ", "))
+			}
+		}
+		if item.Type == "code" {
+			if language, ok := utils.NormalizeLanguage(item.Language); ok {
+				content.Language = language
+			} else {
+				content.Language = utils.DetectLanguage(content.Data, content.Title)
+			}
+		}
+
+	case "image", "file":
+		uploaded, err := a.upload(cleanArchivePath(item.Path))
+		if err != nil {
+			return models.Content{}, nil, err
+		}
+		if item.Type == "image" && !strings.HasPrefix(uploaded.MimeType, "image/") {
+			return models.Content{}, nil, fmt.Errorf("文件 %s 不是图片", item.Path)
+		}
+		content.Data = uploaded.Path
+		if item.Type == "file" {
+			content.FileName = item.FileName
+			if content.FileName == "" {
+				content.FileName = path.Base(item.Path)
+			}
+			content.MimeType = uploaded.MimeType
+			content.FileSize = uploaded.Size
+		}
+
+	default:
+		return models.Content{}, nil, fmt.Errorf("无效的内容类型: %s", item.Type)
+	}
+
+	tags, err := ParseTags(strings.Join(item.Tags, ","))
+	if err != nil {
+		return models.Content{}, nil, err
+	}
+	if content.Tags, err = ResolveTags(tags); err != nil {
+		return models.Content{}, nil, err
+	}
+
+	return content, notes, nil
+}
+
+// restoreUploadLinks 将导出时Markdown中引用的上传文件重新保存，并把链接替换为新的文件路径
+// 返回替换后的Markdown和归档中缺少的文件
+func (a *importArchive) restoreUploadLinks(markdown string) (string, []string) {
+	var missing []string
+	for _, filePath := range ExtractUploadPaths("markdown", markdown) {
+		uploaded, err := a.upload(ArchiveUploadPath(filePath))
+		if err != nil {
+			missing = append(missing, filePath)
+			continue
+		}
+		if uploaded.Path != filePath {
+			markdown = strings.ReplaceAll(markdown, "/"+filePath, "/"+uploaded.Path)
+		}
+	}
+	return markdown, missing
+}
+
+// importFiles 没有清单时按文件类型逐个导入归档中的文件
+// Markdown中以相对路径引用的图片会作为Markdown的一部分保存，不再单独导入
+func (a *importArchive) importFiles() []ImportResult {
+	names := make([]string, 0, len(a.files))
+	for name := range a.files {
+		if isIgnoredArchiveFile(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]ImportResult, 0, len(names))
+	embedded := make(map[string]bool)
+
+	// 先导入Markdown，记录其引用的文件
+	for _, name := range names {
+		if textFileTypes[strings.ToLower(path.Ext(name))] != "markdown" {
+			continue
+		}
+
+		result := ImportResult{Name: name}
+		content, err := a.contentFromFile(name, "markdown")
+		if err == nil {
+			var links []string
+			content.Data, links = a.restoreRelativeLinks(name, content.Data)
+			for _, link := range links {
+				embedded[link] = true
+			}
+			result = a.save(result, content, "")
+		} else {
+			result.Status = ImportStatusFailed
+			result.Message = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	for _, name := range names {
+		contentType := textFileTypes[strings.ToLower(path.Ext(name))]
+		if contentType == "markdown" || embedded[name] {
+			continue
+		}
+
+		result := ImportResult{Name: name}
+		content, err := a.contentFromFile(name, contentType)
+		if err == nil {
+			result = a.save(result, content, "")
+		} else {
+			result.Status = ImportStatusFailed
+			result.Message = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// isIgnoredArchiveFile 判断是否为压缩工具或系统生成的文件
+func isIgnoredArchiveFile(name string) bool {
+	base := path.Base(name)
+	return strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(base, ".") || base == "Thumbs.db"
+}
+
+// contentFromFile 根据归档中的文件构造内容，contentType 为空时根据文件内容识别为代码、图片或文件
+func (a *importArchive) contentFromFile(name string, contentType string) (models.Content, error) {
+	file := a.files[name]
+	base := path.Base(name)
+
+	content := models.Content{
+		Source:     a.source,
+		IsPublic:   a.opts.IsPublic,
+		CreateTime: file.Modified,
+		UpdateTime: file.Modified,
+	}
+	if content.CreateTime.IsZero() || content.CreateTime.After(time.Now()) {
+		content.CreateTime = time.Now()
+		content.UpdateTime = content.CreateTime
+	}
+
+	// 扩展名无法确定类型时，可以识别出语言的文本文件作为代码导入，SVG等图片除外
+	if contentType == "" && !strings.HasPrefix(mime.TypeByExtension(path.Ext(base)), "image/") {
+		if language := utils.DetectLanguage("", base); language != utils.PlainTextLanguage {
+			data, err := a.read(name)
+			if err != nil {
+				return models.Content{}, err
+			}
+			if utf8.Valid(data) {
+				content.Type = "code"
+				content.Data = string(data)
+				content.Language = language
+				content.Title = base
+				return content, nil
+			}
+		}
+	}
+
+	switch contentType {
+	case "markdown", "text":
+		data, err := a.read(name)
+		if err != nil {
+			return models.Content{}, err
+		}
+		if !utf8.Valid(data) {
+			return models.Content{}, fmt.Errorf("文件不是有效的UTF-8文本")
+		}
+		content.Type = contentType
+		content.Data = string(data)
+		content.Title = strings.TrimSuffix(base, path.Ext(base))
+		return content, nil
+	}
+
+	uploaded, err := a.upload(name)
+	if err != nil {
+		return models.Content{}, err
+	}
+	content.Data = uploaded.Path
+	if strings.HasPrefix(uploaded.MimeType, "image/") {
+		content.Type = "image"
+		content.Title = "图片: " + base
+	} else {
+		content.Type = "file"
+		content.Title = "文件: " + base
+		content.FileName = base
+		content.MimeType = uploaded.MimeType
+		content.FileSize = uploaded.Size
+	}
+
+	return content, nil
+}
+
+// restoreRelativeLinks 将Markdown中以相对路径引用的归档文件保存为上传文件，并替换为上传文件的路径
+// 返回替换后的Markdown和被引用的归档文件
+func (a *importArchive) restoreRelativeLinks(name string, markdown string) (string, []string) {
+	var links []string
+	dir := path.Dir(name)
+
+	rewritten := relativeLinkPattern.ReplaceAllStringFunc(markdown, func(match string) string {
+		parts := relativeLinkPattern.FindStringSubmatch(match)
+		prefix, target := parts[1], parts[2]
+
+		// 只处理相对路径，完整URL、站内绝对路径和锚点保持不变
+		if strings.Contains(target, ":") || strings.HasPrefix(target, "/") || strings.HasPrefix(target, "#") {
+			return match
+		}
+
+		linked := cleanArchivePath(path.Join(dir, target))
+		if _, ok := a.files[linked]; !ok || linked == name {
+			return match
+		}
+		uploaded, err := a.upload(linked)
+		if err != nil {
+			return match
+		}
+
+		links = append(links, linked)
+		return prefix + "/" + uploaded.Path
+	})
+
+	return rewritten, links
+}
+
+// save 保存导入的内容，preferredID 未被占用时作为短链接ID
+func (a *importArchive) save(result ImportResult, content models.Content, preferredID string) ImportResult {
+	shortID := ""
+	if a.opts.PreserveIDs && reservedShortIDs[preferredID] {
+		result.Status = ImportStatusRenamed
+		result.Message = fmt.Sprintf("短链接ID %s 与系统路径冲突，已使用新的ID", preferredID)
+	} else if a.opts.PreserveIDs && isValidShortID(preferredID) {
+		var existing models.Content
+		if DB.Where("short_id = ?", preferredID).First(&existing).Error != nil {
+			shortID = preferredID
+		} else if existing.Source == a.source && existing.Type == content.Type && existing.Data == content.Data {
+			// 重复导入同一个归档时跳过已经存在的内容
+			result.ShortID = existing.ShortID
+			result.Status = ImportStatusSkipped
+			result.Message = "相同的内容已存在"
+			return result
+		} else {
+			result.Status = ImportStatusRenamed
+			result.Message = fmt.Sprintf("短链接ID %s 已被占用，已使用新的ID", preferredID)
+		}
+	}
+
+	if shortID == "" {
+		var err error
+		if shortID, err = generateFreeShortID(); err != nil {
+			result.Status = ImportStatusFailed
+			result.Message = err.Error()
+			return result
+		}
+	}
+
+	if err := SaveContent(shortID, content); err != nil {
+		result.Status = ImportStatusFailed
+		result.Message = err.Error()
+		return result
+	}
+
+	result.ShortID = shortID
+	if result.Status == "" {
+		result.Status = ImportStatusImported
+	}
+	return result
+}
+
+// reservedShortIDs 与顶层路由同名的路径，作为短链接ID会被对应路由截获
+var reservedShortIDs = map[string]bool{
+	"admin":      true,
+	"api":        true,
+	"c":          true,
+	"edit":       true,
+	"healthz":    true,
+	"login":      true,
+	"my-content": true,
+	"public":     true,
+	"search":     true,
+	"static":     true,
+	"tags":       true,
+	"uploads":    true,
+}
+
+// isValidShortID 判断是否为可以使用的短链接ID
+func isValidShortID(shortID string) bool {
+	if shortID == "" || len(shortID) > 15 || reservedShortIDs[shortID] {
+		return false
+	}
+	for _, r := range shortID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// generateFreeShortID 生成未被占用的短链接ID
+func generateFreeShortID() (string, error) {
+	for i := 0; i < 5; i++ {
+		shortID := NewShortID()
+		var count int64
+		DB.Model(&models.Content{}).Where("short_id = ?", shortID).Count(&count)
+		if count == 0 {
+			return shortID, nil
+		}
+	}
+	return "", errors.New("生成短链接ID失败")
+}
+
+// sizeLimitedReader 读取超过 remaining 字节时返回错误，避免压缩炸弹
+type sizeLimitedReader struct {
+	io.ReadCloser
+	remaining int64
+}
+
+// Read 读取数据并扣减剩余可读字节数
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, errImportFileTooLarge
+	}
+	return n, err
+}
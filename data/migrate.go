@@ -0,0 +1,188 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sharesth/models"
+)
+
+// Migration 一次数据库结构变更，Up 和 Down 在同一个事务中执行并更新迁移记录
+// SQLite和PostgreSQL的DDL可以在事务中回滚，迁移失败时数据库保持原样；
+// MySQL执行DDL时会隐式提交，失败时已执行的变更保留且不写入迁移记录，重新执行时 Up 和 Down 需要跳过已完成的变更
+// 已发布的迁移不能再修改，结构变化需要追加新的迁移
+type Migration struct {
+	Version int                  // 版本号，从1开始递增
+	Name    string               // 迁移名称，如 "baseline"
+	Up      func(*gorm.DB) error // 应用变更
+	Down    func(*gorm.DB) error // 撤销变更
+}
+
+// MigrationStatus 一个迁移的执行状态
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time // 未执行时为空
+	Unknown   bool       // 数据库中有记录但程序中没有该迁移，通常是数据库被更新版本的程序迁移过
+}
+
+// ErrSchemaBehind 数据库中有未执行的迁移
+var ErrSchemaBehind = errors.New("数据库结构版本落后")
+
+// latestSchemaVersion 返回程序支持的最新结构版本
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// findMigration 根据版本号查找迁移
+func findMigration(version int) (Migration, bool) {
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// appliedMigrations 返回已执行的迁移记录，按版本号升序排列
+func appliedMigrations() ([]models.SchemaMigration, error) {
+	// 迁移记录表本身不纳入版本管理
+	if err := DB.AutoMigrate(&models.SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %v", err)
+	}
+
+	var applied []models.SchemaMigration
+	if err := DB.Order("version").Find(&applied).Error; err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %v", err)
+	}
+	return applied, nil
+}
+
+// MigrateUp 按版本顺序执行所有未执行的迁移，返回本次执行的迁移
+func MigrateUp() ([]Migration, error) {
+	applied, err := appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[int]bool, len(applied))
+	for _, record := range applied {
+		done[record.Version] = true
+	}
+
+	var ran []Migration
+	for _, migration := range migrations {
+		if done[migration.Version] {
+			continue
+		}
+
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&models.SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return ran, fmt.Errorf("执行迁移 %04d_%s 失败: %v", migration.Version, migration.Name, err)
+		}
+
+		log.Printf("已执行迁移 %04d_%s", migration.Version, migration.Name)
+		ran = append(ran, migration)
+	}
+
+	return ran, nil
+}
+
+// MigrateDown 按版本倒序撤销最近执行的 steps 个迁移，返回本次撤销的迁移
+func MigrateDown(steps int) ([]Migration, error) {
+	applied, err := appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(applied) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration, ok := findMigration(applied[i].Version)
+		if !ok {
+			return reverted, fmt.Errorf("数据库中的迁移 %04d_%s 不在当前程序中，请使用执行该迁移的程序版本撤销", applied[i].Version, applied[i].Name)
+		}
+
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&models.SchemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("撤销迁移 %04d_%s 失败: %v", migration.Version, migration.Name, err)
+		}
+
+		log.Printf("已撤销迁移 %04d_%s", migration.Version, migration.Name)
+		reverted = append(reverted, migration)
+	}
+
+	return reverted, nil
+}
+
+// FindMigrationStatus 返回所有迁移的执行状态，按版本号升序排列
+func FindMigrationStatus() ([]MigrationStatus, error) {
+	applied, err := appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[int]time.Time, len(applied))
+	for _, record := range applied {
+		appliedAt[record.Version] = record.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if at, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range applied {
+		if _, ok := findMigration(record.Version); !ok {
+			at := record.AppliedAt
+			statuses = append(statuses, MigrationStatus{Version: record.Version, Name: record.Name, AppliedAt: &at, Unknown: true})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// CheckSchema 检查数据库结构是否与程序一致，有未执行的迁移时返回 ErrSchemaBehind
+func CheckSchema() error {
+	statuses, err := FindMigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.Unknown {
+			return fmt.Errorf("数据库包含程序不认识的迁移 %04d_%s，请升级程序", status.Version, status.Name)
+		}
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w：有%d个迁移未执行（最新版本 %d），请先运行 sharesth migrate up", ErrSchemaBehind, pending, latestSchemaVersion())
+	}
+
+	return nil
+}
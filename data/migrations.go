@@ -0,0 +1,222 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrations 所有数据库迁移，按版本号升序排列
+// MySQL执行DDL时会隐式提交事务，迁移失败时已执行的语句不会回滚，因此每个迁移的 Up 和 Down 都必须可以重复执行
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		Up:      migrateBaselineUp,
+		Down:    migrateBaselineDown,
+	},
+	{
+		Version: 2,
+		Name:    "widen_user_id_columns",
+		Up: func(tx *gorm.DB) error {
+			return alterUserIDColumns(tx, "varchar(32)")
+		},
+		Down: func(tx *gorm.DB) error {
+			return alterUserIDColumns(tx, "varchar(10)")
+		},
+	},
+	{
+		Version: 3,
+		Name:    "add_content_locked",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&contentLockColumn{}, "Locked") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&contentLockColumn{}, "Locked")
+		},
+		Down: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&contentLockColumn{}, "Locked") {
+				return nil
+			}
+			return tx.Migrator().DropColumn(&contentLockColumn{}, "Locked")
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add_report_reporter_ip",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&reportIPColumn{}, "ReporterIP") {
+				if err := tx.Migrator().AddColumn(&reportIPColumn{}, "ReporterIP"); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasIndex(&reportIPColumn{}, "ReporterIP") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&reportIPColumn{}, "ReporterIP")
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&reportIPColumn{}, "ReporterIP") {
+				if err := tx.Migrator().DropIndex(&reportIPColumn{}, "ReporterIP"); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasColumn(&reportIPColumn{}, "ReporterIP") {
+				return nil
+			}
+			return tx.Migrator().DropColumn(&reportIPColumn{}, "ReporterIP")
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add_search_index",
+		Up:      createSearchIndex,
+		Down:    dropSearchIndex,
+	},
+	{
+		Version: 6,
+		Name:    "add_file_md5_uploaded_at",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&fileMD5UploadedAtColumn{}, "UploadedAt") {
+				if err := tx.Migrator().AddColumn(&fileMD5UploadedAtColumn{}, "UploadedAt"); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasIndex(&fileMD5UploadedAtColumn{}, "UploadedAt") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&fileMD5UploadedAtColumn{}, "UploadedAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&fileMD5UploadedAtColumn{}, "UploadedAt") {
+				if err := tx.Migrator().DropIndex(&fileMD5UploadedAtColumn{}, "UploadedAt"); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasColumn(&fileMD5UploadedAtColumn{}, "UploadedAt") {
+				return nil
+			}
+			return tx.Migrator().DropColumn(&fileMD5UploadedAtColumn{}, "UploadedAt")
+		},
+	},
+	{
+		Version: 7,
+		Name:    "exclude_view_limited_from_search_index",
+		Up:      rebuildSearchIndex,
+		// 新的索引只是不再包含访问次数受限内容的正文，旧版本程序可以直接使用，撤销时保留
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	},
+	{
+		Version: 8,
+		Name:    "add_collection_locked",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&collectionLockColumn{}, "Locked") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&collectionLockColumn{}, "Locked")
+		},
+		Down: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&collectionLockColumn{}, "Locked") {
+				return nil
+			}
+			return tx.Migrator().DropColumn(&collectionLockColumn{}, "Locked")
+		},
+	},
+}
+
+// contentLockColumn 迁移3为 contents 表增加的列
+type contentLockColumn struct {
+	Locked bool `gorm:"default:false"`
+}
+
+func (contentLockColumn) TableName() string { return "contents" }
+
+// reportIPColumn 迁移4为 reports 表增加的列
+type reportIPColumn struct {
+	ReporterIP string `gorm:"type:varchar(45);index"`
+}
+
+func (reportIPColumn) TableName() string { return "reports" }
+
+// fileMD5UploadedAtColumn 迁移6为 file_md5s 表增加的列
+type fileMD5UploadedAtColumn struct {
+	UploadedAt *time.Time `gorm:"index"`
+}
+
+func (fileMD5UploadedAtColumn) TableName() string { return "file_md5s" }
+
+// collectionLockColumn 迁移8为 collections 表增加的列
+type collectionLockColumn struct {
+	Locked bool `gorm:"default:false"`
+}
+
+func (collectionLockColumn) TableName() string { return "collections" }
+
+// migrateBaselineUp 创建引入版本化迁移时的全部表
+// 之前由 AutoMigrate 创建的数据库已有这些表，此时只补全缺少的表和列，因此可以直接在旧数据库上执行
+func migrateBaselineUp(tx *gorm.DB) error {
+	// 文件引用表首次创建时需要根据现有内容补全引用
+	backfillReferences := tx.Migrator().HasTable("contents") && !tx.Migrator().HasTable("upload_references")
+
+	if err := tx.AutoMigrate(baselineTables...); err != nil {
+		return err
+	}
+
+	if backfillReferences {
+		return backfillUploadReferences(tx)
+	}
+	return nil
+}
+
+// migrateBaselineDown 删除基线中的全部表，所有数据都会丢失
+func migrateBaselineDown(tx *gorm.DB) error {
+	// 倒序删除，先删除引用其他表的表
+	for i := len(baselineTables) - 1; i >= 0; i-- {
+		if err := tx.Migrator().DropTable(baselineTables[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userIDColumns 保存用户ID（客户端标识）的列，用户ID在冲突过多时会超过基线中的10个字符
+var userIDColumns = []struct {
+	table  string
+	column string
+}{
+	{"contents", "source"},
+	{"collections", "source"},
+	{"content_revisions", "editor"},
+	{"users", "user_id"},
+	{"user_fingerprints", "user_id"},
+	{"identity_merges", "from_user_id"},
+	{"identity_merges", "to_user_id"},
+	{"bans", "source"},
+	{"reports", "reporter"},
+}
+
+// alterUserIDColumns 修改所有用户ID列的类型
+// 缩短长度时若已有更长的数据，PostgreSQL和MySQL会报错。PostgreSQL的迁移随事务回滚；
+// MySQL中已修改的列不会回滚，修改为相同类型不会改变数据，修正数据后重新执行迁移即可
+func alterUserIDColumns(tx *gorm.DB, columnType string) error {
+	var statement string
+	switch tx.Dialector.Name() {
+	case "postgres":
+		statement = "ALTER TABLE %s ALTER COLUMN %s TYPE %s"
+	case "mysql":
+		statement = "ALTER TABLE %s MODIFY %s %s"
+	default:
+		// SQLite不限制 varchar 的长度，无需修改
+		return nil
+	}
+
+	for _, c := range userIDColumns {
+		if err := tx.Exec(fmt.Sprintf(statement, c.table, c.column, columnType)).Error; err != nil {
+			return fmt.Errorf("修改 %s.%s 失败: %v", c.table, c.column, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,456 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sharesth/models"
+	"sharesth/utils"
+)
+
+// 审计日志中的操作类型
+const (
+	AuditActionUnpublish = "unpublish"
+	AuditActionUnlock    = "unlock"
+	AuditActionDelete    = "delete"
+	AuditActionBan       = "ban"
+	AuditActionUnban     = "unban"
+
+	AuditActionAutoHide       = "auto_hide"
+	AuditActionDismissReports = "dismiss_reports"
+	AuditActionUpholdReports  = "uphold_reports"
+)
+
+// 审计日志中的操作对象类型
+const (
+	AuditTargetContent    = "content"
+	AuditTargetCollection = "collection"
+	AuditTargetSource     = "source"
+)
+
+// moderationEditor 管理员修改内容时版本记录中的编辑者
+const moderationEditor = "admin"
+
+// ErrSourceBanned 来源已被封禁，不能发布内容
+var ErrSourceBanned = errors.New("您已被禁止发布内容")
+
+// AdminContentFilter 管理后台内容列表的筛选条件，空字段表示不筛选
+type AdminContentFilter struct {
+	Source     string // 来源标识
+	Type       string // 内容类型
+	Tag        string // 标签
+	Query      string // 搜索关键词
+	Visibility string // "public" 或 "private"
+}
+
+// RecordAudit 在事务中记录一条管理员操作日志，同时输出到服务日志
+func RecordAudit(tx *gorm.DB, actor string, action string, targetType string, targetID string, detail string) error {
+	entry := models.AuditLog{
+		Actor:      actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Detail:     detail,
+		CreatedAt:  time.Now(),
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("记录审计日志失败: %v", err)
+	}
+
+	log.Printf("管理员操作: %s %s %s:%s %s", actor, action, targetType, targetID, detail)
+	return nil
+}
+
+// FindAuditLogs 分页查询审计日志，按时间倒序排列
+func FindAuditLogs(action string, page int, perPage int) (int64, []models.AuditLog) {
+	db := DB.Model(&models.AuditLog{})
+	if action != "" {
+		db = db.Where("action = ?", action)
+	}
+
+	var total int64
+	db.Count(&total)
+
+	logs := make([]models.AuditLog, 0)
+	db.Order("created_at DESC, id DESC").Offset((page - 1) * perPage).Limit(perPage).Find(&logs)
+
+	return total, logs
+}
+
+// FindAllContentsPaginated 分页查找所有来源的内容，用于管理后台
+func FindAllContentsPaginated(filter AdminContentFilter, page int, perPage int) (int64, []map[string]interface{}) {
+	var contents []models.Content
+	db := DB.Model(&models.Content{})
+
+	if filter.Query != "" {
+		db = searchContents(db, filter.Query)
+	} else {
+		db = db.Order("contents.create_time DESC")
+	}
+	if filter.Source != "" {
+		db = db.Where("contents.source = ?", filter.Source)
+	}
+	if filter.Type != "" && filter.Type != "all" {
+		db = db.Where("contents.type = ?", filter.Type)
+	}
+	if filter.Tag != "" {
+		db = db.Scopes(withTag(strings.ToLower(filter.Tag)))
+	}
+	switch filter.Visibility {
+	case "public":
+		db = db.Where("contents.is_public = ?", true)
+	case "private":
+		db = db.Where("contents.is_public = ?", false)
+	}
+
+	var total int64
+	db.Count(&total)
+
+	db.Preload("Tags").Offset((page - 1) * perPage).Limit(perPage).Find(&contents)
+
+	// 标记已被封禁的来源
+	banned := findBannedSources(contents)
+
+	results := make([]map[string]interface{}, 0, len(contents))
+	for _, content := range contents {
+		item := map[string]interface{}{
+			"id":            content.ID,
+			"short_id":      content.ShortID,
+			"type":          content.Type,
+			"createTime":    content.CreateTime,
+			"updateTime":    content.UpdateTime,
+			"link":          content.ShortID,
+			"title":         content.Title,
+			"source":        content.Source,
+			"is_public":     content.IsPublic,
+			"under_review":  content.UnderReview,
+			"locked":        content.Locked,
+			"expires_at":    content.ExpiresAt,
+			"max_views":     content.MaxViews,
+			"view_count":    content.ViewCount,
+			"tags":          content.TagNames(),
+			"source_banned": banned[content.Source],
+		}
+
+		// 根据内容类型添加不同的额外字段
+		addContentPreview(item, content)
+
+		results = append(results, item)
+	}
+
+	return total, results
+}
+
+// FindAllCollectionsPaginated 分页查找所有来源的合集，用于管理后台
+// query 按标题模糊匹配，visibility 为 "public" 或 "private"，空字段表示不筛选
+func FindAllCollectionsPaginated(source string, query string, visibility string, page int, perPage int) (int64, []map[string]interface{}) {
+	db := DB.Model(&models.Collection{})
+	if source != "" {
+		db = db.Where("collections.source = ?", source)
+	}
+	if query != "" {
+		db = db.Where(titleLikeCondition("collections"), "%"+query+"%")
+	}
+	switch visibility {
+	case "public":
+		db = db.Where("collections.is_public = ?", true)
+	case "private":
+		db = db.Where("collections.is_public = ?", false)
+	}
+
+	var total int64
+	db.Count(&total)
+
+	var collections []struct {
+		models.Collection
+		ItemCount int64 `gorm:"column:item_count"`
+	}
+	db.Select("collections.*, (SELECT count(*) FROM collection_items WHERE collection_items.collection_id = collections.id) AS item_count").
+		Order("collections.update_time DESC").
+		Offset((page - 1) * perPage).Limit(perPage).
+		Scan(&collections)
+
+	sources := make([]string, 0, len(collections))
+	for _, collection := range collections {
+		sources = append(sources, collection.Source)
+	}
+	banned := findBannedSourcesIn(sources)
+
+	results := make([]map[string]interface{}, 0, len(collections))
+	for _, collection := range collections {
+		results = append(results, map[string]interface{}{
+			"id":            collection.ID,
+			"short_id":      collection.ShortID,
+			"title":         collection.Title,
+			"description":   collection.Description,
+			"source":        collection.Source,
+			"is_public":     collection.IsPublic,
+			"locked":        collection.Locked,
+			"createTime":    collection.CreateTime,
+			"updateTime":    collection.UpdateTime,
+			"link":          "c/" + collection.ShortID,
+			"item_count":    collection.ItemCount,
+			"source_banned": banned[collection.Source],
+		})
+	}
+
+	return total, results
+}
+
+// findBannedSources 返回内容列表中已被封禁的来源
+func findBannedSources(contents []models.Content) map[string]bool {
+	sources := make([]string, 0, len(contents))
+	for _, content := range contents {
+		sources = append(sources, content.Source)
+	}
+	return findBannedSourcesIn(sources)
+}
+
+// findBannedSourcesIn 返回给定来源中已被封禁的来源
+func findBannedSourcesIn(sources []string) map[string]bool {
+	banned := make(map[string]bool)
+	if len(sources) == 0 {
+		return banned
+	}
+
+	var bannedSources []string
+	DB.Model(&models.Ban{}).Where("source IN ?", sources).Pluck("source", &bannedSources)
+	for _, source := range bannedSources {
+		banned[source] = true
+	}
+
+	return banned
+}
+
+// UnpublishContent 管理员将内容设为不公开并锁定，解除锁定前作者不能重新公开，并记录审计日志
+func UnpublishContent(shortID string, actor string, reason string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var content models.Content
+		if err := tx.Where("short_id = ?", shortID).First(&content).Error; err != nil {
+			return fmt.Errorf("内容不存在")
+		}
+		// 因举报被自动隐藏的内容不公开但仍可能被驳回举报恢复，也允许锁定
+		if content.Locked || (!content.IsPublic && !content.UnderReview) {
+			return fmt.Errorf("内容已经是不公开状态")
+		}
+
+		content.IsPublic = false
+		content.UnderReview = false
+		content.Locked = true
+		content.UpdateTime = time.Now()
+		if err := saveContentRevisionTx(tx, &content, moderationEditor, "管理员取消公开"); err != nil {
+			return err
+		}
+
+		return RecordAudit(tx, actor, AuditActionUnpublish, AuditTargetContent, shortID, reason)
+	})
+}
+
+// UnlockContent 解除管理员取消公开时的锁定，内容保持不公开，由作者决定是否重新公开
+func UnlockContent(shortID string, actor string, reason string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var content models.Content
+		if err := tx.Where("short_id = ?", shortID).First(&content).Error; err != nil {
+			return fmt.Errorf("内容不存在")
+		}
+		if !content.Locked {
+			return fmt.Errorf("内容没有被锁定")
+		}
+
+		if err := tx.Model(&content).UpdateColumn("locked", false).Error; err != nil {
+			return fmt.Errorf("解除锁定失败: %v", err)
+		}
+
+		return RecordAudit(tx, actor, AuditActionUnlock, AuditTargetContent, shortID, reason)
+	})
+}
+
+// RemoveContent 管理员删除任意来源的内容，并记录审计日志
+func RemoveContent(shortID string, actor string, reason string) error {
+	var paths []string
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var content models.Content
+		if err := tx.Where("short_id = ?", shortID).First(&content).Error; err != nil {
+			return fmt.Errorf("内容不存在")
+		}
+
+		var err error
+		if paths, err = deleteContentRecords(tx, content); err != nil {
+			return err
+		}
+
+		detail := strings.Join(utils.FilterEmpty([]string{reason, fmt.Sprintf("来源: %s，标题: %s", content.Source, content.Title)}), "；")
+		return RecordAudit(tx, actor, AuditActionDelete, AuditTargetContent, shortID, detail)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 上传的文件在没有其他引用时删除
+	releaseUploadedFiles(paths)
+
+	return nil
+}
+
+// UnpublishCollection 管理员将合集设为不公开并锁定，解除锁定前创建者不能重新公开，并记录审计日志
+func UnpublishCollection(shortID string, actor string, reason string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var collection models.Collection
+		if err := tx.Where("short_id = ?", shortID).First(&collection).Error; err != nil {
+			return fmt.Errorf("合集不存在")
+		}
+		if collection.Locked || !collection.IsPublic {
+			return fmt.Errorf("合集已经是不公开状态")
+		}
+
+		err := tx.Model(&collection).Updates(map[string]interface{}{
+			"is_public":   false,
+			"locked":      true,
+			"update_time": time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("取消公开合集失败: %v", err)
+		}
+
+		return RecordAudit(tx, actor, AuditActionUnpublish, AuditTargetCollection, shortID, reason)
+	})
+}
+
+// UnlockCollection 解除合集的锁定，合集保持不公开，由创建者决定是否重新公开
+func UnlockCollection(shortID string, actor string, reason string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var collection models.Collection
+		if err := tx.Where("short_id = ?", shortID).First(&collection).Error; err != nil {
+			return fmt.Errorf("合集不存在")
+		}
+		if !collection.Locked {
+			return fmt.Errorf("合集没有被锁定")
+		}
+
+		if err := tx.Model(&collection).UpdateColumn("locked", false).Error; err != nil {
+			return fmt.Errorf("解除锁定失败: %v", err)
+		}
+
+		return RecordAudit(tx, actor, AuditActionUnlock, AuditTargetCollection, shortID, reason)
+	})
+}
+
+// RemoveCollection 管理员删除任意来源的合集，合集中的内容不受影响，并记录审计日志
+func RemoveCollection(shortID string, actor string, reason string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var collection models.Collection
+		if err := tx.Where("short_id = ?", shortID).First(&collection).Error; err != nil {
+			return fmt.Errorf("合集不存在")
+		}
+
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&models.CollectionItem{}).Error; err != nil {
+			return fmt.Errorf("删除合集失败: %v", err)
+		}
+		if err := tx.Delete(&collection).Error; err != nil {
+			return fmt.Errorf("删除合集失败: %v", err)
+		}
+
+		detail := strings.Join(utils.FilterEmpty([]string{reason, fmt.Sprintf("来源: %s，标题: %s", collection.Source, collection.Title)}), "；")
+		return RecordAudit(tx, actor, AuditActionDelete, AuditTargetCollection, shortID, detail)
+	})
+}
+
+// IsSourceBanned 判断来源是否已被封禁
+func IsSourceBanned(source string) bool {
+	var count int64
+	DB.Model(&models.Ban{}).Where("source = ?", source).Count(&count)
+	return count > 0
+}
+
+// BanSource 禁止来源发布内容，unpublish 为 true 时同时将其所有公开内容和合集设为不公开
+// 返回被取消公开的内容数量
+func BanSource(source string, actor string, reason string, unpublish bool) (int64, error) {
+	var unpublished int64
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source = ?", source).First(&models.Ban{}).Error; err == nil {
+			return fmt.Errorf("来源 %s 已被封禁", source)
+		}
+
+		ban := models.Ban{
+			Source:    source,
+			Reason:    reason,
+			CreatedBy: actor,
+			CreatedAt: time.Now(),
+		}
+		if err := tx.Create(&ban).Error; err != nil {
+			return fmt.Errorf("封禁来源失败: %v", err)
+		}
+
+		detail := reason
+		if unpublish {
+			result := tx.Model(&models.Content{}).
+				Where("source = ? AND is_public = ?", source, true).
+				Updates(map[string]interface{}{"is_public": false, "update_time": time.Now()})
+			if result.Error != nil {
+				return fmt.Errorf("取消公开内容失败: %v", result.Error)
+			}
+			unpublished = result.RowsAffected
+
+			// 合集的标题和描述同样会公开展示，一并取消公开
+			result = tx.Model(&models.Collection{}).
+				Where("source = ? AND is_public = ?", source, true).
+				Updates(map[string]interface{}{"is_public": false, "update_time": time.Now()})
+			if result.Error != nil {
+				return fmt.Errorf("取消公开合集失败: %v", result.Error)
+			}
+			detail = strings.Join(utils.FilterEmpty([]string{reason, fmt.Sprintf("取消公开%d个内容、%d个合集", unpublished, result.RowsAffected)}), "；")
+		}
+
+		return RecordAudit(tx, actor, AuditActionBan, AuditTargetSource, source, detail)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return unpublished, nil
+}
+
+// UnbanSource 解除来源的封禁
+func UnbanSource(source string, actor string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("source = ?", source).Delete(&models.Ban{})
+		if result.Error != nil {
+			return fmt.Errorf("解除封禁失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("来源 %s 未被封禁", source)
+		}
+
+		return RecordAudit(tx, actor, AuditActionUnban, AuditTargetSource, source, "")
+	})
+}
+
+// FindBans 返回所有被封禁的来源及其内容数量，最近封禁的在前
+func FindBans() []map[string]interface{} {
+	var bans []struct {
+		models.Ban
+		ContentCount int64 `gorm:"column:content_count"`
+	}
+	DB.Model(&models.Ban{}).
+		Select("bans.*, (SELECT count(*) FROM contents WHERE contents.source = bans.source) AS content_count").
+		Order("created_at DESC").
+		Scan(&bans)
+
+	results := make([]map[string]interface{}, 0, len(bans))
+	for _, ban := range bans {
+		results = append(results, map[string]interface{}{
+			"source":        ban.Source,
+			"reason":        ban.Reason,
+			"created_by":    ban.CreatedBy,
+			"created_at":    ban.CreatedAt,
+			"content_count": ban.ContentCount,
+		})
+	}
+
+	return results
+}
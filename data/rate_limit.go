@@ -0,0 +1,191 @@
+package data
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RateLimit 令牌桶限流规则：桶容量为 Limit，每 Period 补满
+type RateLimit struct {
+	Name   string        // 规则名称，用于区分不同路由的计数
+	Limit  int           // 桶容量，即一个周期内允许的最大请求数
+	Period time.Duration // 令牌从空到补满所需的时间
+}
+
+// refillPerSecond 返回每秒补充的令牌数
+func (l RateLimit) refillPerSecond() float64 {
+	return float64(l.Limit) / l.Period.Seconds()
+}
+
+// RateLimitResult 一次限流检查的结果
+type RateLimitResult struct {
+	Allowed    bool          // 是否允许本次请求
+	Limit      int           // 桶容量
+	Remaining  int           // 剩余令牌数
+	Reset      time.Duration // 令牌补满所需的时间
+	RetryAfter time.Duration // 被拒绝时距离下一个令牌可用的时间
+}
+
+// Redis不可用后改用内存限流，经过这段时间再重试Redis
+const rateLimitRedisRetryInterval = 30 * time.Second
+
+// 内存令牌桶的清理间隔
+const rateLimitSweepInterval = time.Minute
+
+// rateLimitScript 在Redis中原子地补充并获取令牌，桶以哈希保存剩余令牌数和上次更新时间（毫秒）
+// 返回 {是否允许, 剩余令牌数}，令牌数为小数，以字符串返回避免被截断为整数
+var rateLimitScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`)
+
+// tokenBucket 内存中的令牌桶
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+var (
+	rateLimitMu      sync.Mutex
+	memoryBuckets    = make(map[string]*tokenBucket)
+	lastBucketSweep  time.Time
+	redisRateLimitAt time.Time // 在此之前不使用Redis限流
+)
+
+// TakeRateLimitToken 从 key 对应的令牌桶中获取一个令牌
+// 优先使用Redis保存令牌桶，多个实例共享计数；Redis不可用时退回到进程内的令牌桶
+func TakeRateLimitToken(key string, limit RateLimit) RateLimitResult {
+	now := time.Now()
+	key = "ratelimit:" + limit.Name + ":" + key
+
+	rateLimitMu.Lock()
+	useRedis := RedisClient != nil && !now.Before(redisRateLimitAt)
+	rateLimitMu.Unlock()
+
+	if useRedis {
+		tokens, allowed, err := takeRedisToken(key, limit, now)
+		if err == nil {
+			return newRateLimitResult(limit, tokens, allowed)
+		}
+
+		log.Printf("Redis限流失败，改用内存限流: %v", err)
+		rateLimitMu.Lock()
+		redisRateLimitAt = now.Add(rateLimitRedisRetryInterval)
+		rateLimitMu.Unlock()
+	}
+
+	tokens, allowed := takeMemoryToken(key, limit, now)
+	return newRateLimitResult(limit, tokens, allowed)
+}
+
+// takeRedisToken 在Redis中获取令牌，返回剩余令牌数和是否允许
+func takeRedisToken(key string, limit RateLimit, now time.Time) (float64, bool, error) {
+	args := []interface{}{
+		limit.Limit,
+		limit.refillPerSecond() / 1000,
+		now.UnixMilli(),
+		limit.Period.Milliseconds(),
+	}
+	result, err := rateLimitScript.Run(Ctx, RedisClient, []string{key}, args...).Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	if len(result) != 2 {
+		return 0, false, fmt.Errorf("限流脚本返回值无效: %v", result)
+	}
+
+	allowed, _ := result[0].(int64)
+	tokensText, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("解析剩余令牌数失败: %v", err)
+	}
+
+	return tokens, allowed == 1, nil
+}
+
+// takeMemoryToken 在进程内的令牌桶中获取令牌，返回剩余令牌数和是否允许
+func takeMemoryToken(key string, limit RateLimit, now time.Time) (float64, bool) {
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+
+	// 定期清理已经补满的令牌桶，它们与不存在的桶等价
+	if now.Sub(lastBucketSweep) >= rateLimitSweepInterval {
+		for bucketKey, bucket := range memoryBuckets {
+			if now.Sub(bucket.updated) >= bucket.period {
+				delete(memoryBuckets, bucketKey)
+			}
+		}
+		lastBucketSweep = now
+	}
+
+	bucket, ok := memoryBuckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Limit), updated: now, period: limit.Period}
+		memoryBuckets[key] = bucket
+	}
+
+	elapsed := math.Max(0, now.Sub(bucket.updated).Seconds())
+	bucket.tokens = math.Min(float64(limit.Limit), bucket.tokens+elapsed*limit.refillPerSecond())
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		return bucket.tokens, false
+	}
+	bucket.tokens--
+	return bucket.tokens, true
+}
+
+// newRateLimitResult 根据剩余令牌数计算限流结果
+func newRateLimitResult(limit RateLimit, tokens float64, allowed bool) RateLimitResult {
+	rate := limit.refillPerSecond()
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     limit.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(limit.Limit) - tokens) / rate * float64(time.Second)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	return result
+}
+
+// RateLimitBackend 返回当前保存限流计数的位置："redis" 或 "memory"
+func RateLimitBackend() string {
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+
+	if RedisClient != nil && !time.Now().Before(redisRateLimitAt) {
+		return "redis"
+	}
+	return "memory"
+}
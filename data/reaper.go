@@ -0,0 +1,60 @@
+package data
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sharesth/models"
+)
+
+// 过期内容清理间隔
+const ContentReapInterval = time.Minute
+
+// StartContentReaper 启动后台协程，定期清理过期或访问次数已用尽的内容
+func StartContentReaper(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			ReapExpiredContents()
+			<-ticker.C
+		}
+	}()
+}
+
+// ReapExpiredContents 删除所有过期或访问次数已用尽的内容，并释放其上传的文件
+func ReapExpiredContents() int {
+	var contents []models.Content
+	result := DB.Where("(expires_at IS NOT NULL AND expires_at <= ?) OR (max_views > 0 AND view_count >= max_views)", time.Now()).
+		Find(&contents)
+	if result.Error != nil {
+		log.Printf("查询过期内容失败: %v", result.Error)
+		return 0
+	}
+
+	reaped := 0
+	for _, content := range contents {
+		var paths []string
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			paths, err = deleteContentRecords(tx, content)
+			return err
+		})
+		if err != nil {
+			log.Printf("删除过期内容失败: %s, %v", content.ShortID, err)
+			continue
+		}
+		reaped++
+
+		// 上传的文件在没有其他引用时删除
+		releaseUploadedFiles(paths)
+	}
+
+	if reaped > 0 {
+		log.Printf("清理了 %d 条过期内容", reaped)
+	}
+	return reaped
+}
@@ -0,0 +1,268 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sharesth/models"
+	"sharesth/utils"
+)
+
+// 举报类型
+const (
+	ReportCategorySpam        = "spam"        // 垃圾广告
+	ReportCategoryCredentials = "credentials" // 泄露的密码、密钥等凭据
+	ReportCategoryIllegal     = "illegal"     // 违法内容
+	ReportCategoryAbuse       = "abuse"       // 骚扰或辱骂
+	ReportCategoryOther       = "other"       // 其他
+)
+
+// ReportCategories 支持的举报类型及其显示名称
+var ReportCategories = map[string]string{
+	ReportCategorySpam:        "垃圾广告",
+	ReportCategoryCredentials: "泄露凭据",
+	ReportCategoryIllegal:     "违法内容",
+	ReportCategoryAbuse:       "骚扰辱骂",
+	ReportCategoryOther:       "其他",
+}
+
+// 举报的处理状态
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusDismissed = "dismissed"
+	ReportStatusUpheld    = "upheld"
+)
+
+// 举报处理结果
+const (
+	ReportDecisionDismiss = "dismiss" // 举报不成立，恢复被自动隐藏的内容
+	ReportDecisionUphold  = "uphold"  // 举报成立，内容保持不公开
+)
+
+// 举报说明的最大长度
+const maxReportDetailLength = 1000
+
+// reportSystemActor 自动隐藏内容时审计日志中的操作人
+const reportSystemActor = "system"
+
+// ErrContentUnderReview 内容因举报被隐藏，审核完成前不能重新公开
+var ErrContentUnderReview = errors.New("内容正在审核中，暂时不能公开")
+
+// ErrContentLocked 内容被管理员取消公开，管理员解除锁定前不能重新公开
+var ErrContentLocked = errors.New("内容已被管理员取消公开，暂时不能公开")
+
+// ReportHideThreshold 返回自动隐藏公开内容所需的来自不同IP的待处理举报数，为0时不自动隐藏
+func ReportHideThreshold() int {
+	return reportHideThreshold
+}
+
+// CreateReport 记录一次举报，同一来源或同一IP对同一内容只能有一个待处理的举报
+// 客户端标识可以通过更换浏览器特征伪造，因此公开内容收到来自不同IP的待处理举报达到阈值时
+// 才自动设为不公开并等待审核，返回内容是否因此被隐藏
+func CreateReport(shortID string, category string, detail string, reporter string, reporterIP string) (bool, error) {
+	if _, ok := ReportCategories[category]; !ok {
+		return false, fmt.Errorf("不支持的举报类型: %s", category)
+	}
+	detail = strings.TrimSpace(detail)
+	if len([]rune(detail)) > maxReportDetailLength {
+		return false, fmt.Errorf("举报说明不能超过%d个字符", maxReportDetailLength)
+	}
+
+	hidden := false
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var content models.Content
+		if err := tx.Where("short_id = ?", shortID).First(&content).Error; err != nil || content.IsExpired() {
+			return fmt.Errorf("内容不存在")
+		}
+		if content.Source == reporter {
+			return fmt.Errorf("不能举报自己的内容")
+		}
+
+		sameReporter := tx.Where("reporter = ?", reporter)
+		if reporterIP != "" {
+			sameReporter = sameReporter.Or("reporter_ip = ?", reporterIP)
+		}
+		var existing int64
+		tx.Model(&models.Report{}).
+			Where("content_id = ? AND status = ?", content.ID, ReportStatusOpen).
+			Where(sameReporter).
+			Count(&existing)
+		if existing > 0 {
+			return fmt.Errorf("您已举报过该内容，请等待处理")
+		}
+
+		report := models.Report{
+			ContentID:  content.ID,
+			ShortID:    content.ShortID,
+			Category:   category,
+			Detail:     detail,
+			Reporter:   reporter,
+			ReporterIP: reporterIP,
+			Status:     ReportStatusOpen,
+			CreatedAt:  time.Now(),
+		}
+		if err := tx.Create(&report).Error; err != nil {
+			return fmt.Errorf("保存举报失败: %v", err)
+		}
+
+		threshold := ReportHideThreshold()
+		if threshold == 0 || !content.IsPublic || content.UnderReview {
+			return nil
+		}
+
+		// 记录IP之前的举报没有IP，按客户端标识计数
+		var open int64
+		tx.Model(&models.Report{}).
+			Select("COUNT(DISTINCT COALESCE(NULLIF(reporter_ip, ''), reporter))").
+			Where("content_id = ? AND status = ?", content.ID, ReportStatusOpen).
+			Scan(&open)
+		if open < int64(threshold) {
+			return nil
+		}
+
+		// 达到阈值，隐藏内容等待管理员审核
+		content.IsPublic = false
+		content.UnderReview = true
+		content.UpdateTime = time.Now()
+		if err := saveContentRevisionTx(tx, &content, moderationEditor, "举报过多，自动隐藏待审核"); err != nil {
+			return err
+		}
+		hidden = true
+
+		return RecordAudit(tx, reportSystemActor, AuditActionAutoHide, AuditTargetContent, content.ShortID, fmt.Sprintf("来自%d个IP的待处理举报", open))
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return hidden, nil
+}
+
+// FindReportedContents 分页列出有指定状态举报的内容，按最近一次举报时间倒序排列，每项汇总举报数量和各类型的数量
+func FindReportedContents(status string, page int, perPage int) (int64, []map[string]interface{}) {
+	if status == "" {
+		status = ReportStatusOpen
+	}
+
+	// 每个内容一行
+	grouped := func() *gorm.DB {
+		return DB.Model(&models.Report{}).Select("content_id").Where("status = ?", status).Group("content_id")
+	}
+
+	var total int64
+	DB.Table("(?) AS grouped", grouped()).Count(&total)
+
+	var contentIDs []uint
+	grouped().Order("MAX(id) DESC").Offset((page-1)*perPage).Limit(perPage).Pluck("content_id", &contentIDs)
+
+	results := make([]map[string]interface{}, 0, len(contentIDs))
+	if len(contentIDs) == 0 {
+		return total, results
+	}
+
+	var contents []models.Content
+	DB.Where("id IN ?", contentIDs).Find(&contents)
+	contentsByID := make(map[uint]models.Content, len(contents))
+	for _, content := range contents {
+		contentsByID[content.ID] = content
+	}
+
+	var reports []models.Report
+	DB.Where("content_id IN ? AND status = ?", contentIDs, status).Order("id DESC").Find(&reports)
+	reportsByContent := make(map[uint][]models.Report)
+	for _, report := range reports {
+		reportsByContent[report.ContentID] = append(reportsByContent[report.ContentID], report)
+	}
+
+	for _, contentID := range contentIDs {
+		contentReports := reportsByContent[contentID]
+		categories := make(map[string]int)
+		for _, report := range contentReports {
+			categories[report.Category]++
+		}
+
+		content := contentsByID[contentID]
+		results = append(results, map[string]interface{}{
+			"short_id":         content.ShortID,
+			"title":            content.Title,
+			"type":             content.Type,
+			"source":           content.Source,
+			"is_public":        content.IsPublic,
+			"under_review":     content.UnderReview,
+			"report_count":     len(contentReports),
+			"categories":       categories,
+			"last_reported_at": contentReports[0].CreatedAt,
+		})
+	}
+
+	return total, results
+}
+
+// FindContentReports 返回内容的所有举报，最新的在前
+func FindContentReports(shortID string) []models.Report {
+	reports := make([]models.Report, 0)
+	DB.Where("short_id = ?", shortID).Order("id DESC").Find(&reports)
+	return reports
+}
+
+// ResolveReports 处理内容的所有待处理举报
+// dismiss: 举报不成立，被自动隐藏的内容恢复公开；uphold: 举报成立，内容设为不公开并锁定
+func ResolveReports(shortID string, actor string, decision string, note string) error {
+	var status, revisionNote string
+	var action string
+	switch decision {
+	case ReportDecisionDismiss:
+		status, action, revisionNote = ReportStatusDismissed, AuditActionDismissReports, "举报不成立，恢复公开"
+	case ReportDecisionUphold:
+		status, action, revisionNote = ReportStatusUpheld, AuditActionUpholdReports, "举报成立，取消公开并锁定"
+	default:
+		return fmt.Errorf("不支持的处理结果: %s", decision)
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var content models.Content
+		if err := tx.Where("short_id = ?", shortID).First(&content).Error; err != nil {
+			return fmt.Errorf("内容不存在")
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.Report{}).
+			Where("content_id = ? AND status = ?", content.ID, ReportStatusOpen).
+			Updates(map[string]interface{}{"status": status, "resolved_by": actor, "resolved_at": now})
+		if result.Error != nil {
+			return fmt.Errorf("更新举报状态失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 && !content.UnderReview {
+			return fmt.Errorf("该内容没有待处理的举报")
+		}
+
+		// 驳回举报时只恢复被自动隐藏的内容，作者自己设为不公开的内容保持不变
+		isPublic := content.IsPublic || content.UnderReview
+		if decision == ReportDecisionUphold {
+			isPublic = false
+		}
+		// 举报成立的内容锁定，管理员解除锁定前作者不能重新公开
+		locked := content.Locked || decision == ReportDecisionUphold
+		if content.UnderReview || content.IsPublic != isPublic || content.Locked != locked {
+			content.IsPublic = isPublic
+			content.UnderReview = false
+			content.Locked = locked
+			content.UpdateTime = now
+			if err := saveContentRevisionTx(tx, &content, moderationEditor, revisionNote); err != nil {
+				return err
+			}
+		}
+
+		detail := strings.Join(utils.FilterEmpty([]string{note, fmt.Sprintf("处理举报%d个", result.RowsAffected)}), "；")
+		return RecordAudit(tx, actor, action, AuditTargetContent, shortID, detail)
+	})
+}
+
+// deleteContentReports 删除内容的所有举报
+func deleteContentReports(tx *gorm.DB, contentID uint) error {
+	return tx.Where("content_id = ?", contentID).Delete(&models.Report{}).Error
+}
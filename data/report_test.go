@@ -0,0 +1,83 @@
+package data
+
+import (
+	"errors"
+	"testing"
+
+	"sharesth/models"
+)
+
+func TestUpheldReportLocksContent(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T) {
+		content := createTestContent(t, models.Content{ShortID: "reported", Type: "text", Data: "spam", Source: "alice", IsPublic: true})
+		if _, err := CreateReport(content.ShortID, ReportCategorySpam, "", "bob", "192.0.2.1"); err != nil {
+			t.Fatalf("CreateReport: %v", err)
+		}
+		if _, err := CreateReport(content.ShortID, ReportCategorySpam, "", "carol", "192.0.2.1"); err == nil {
+			t.Error("同一IP重复举报应被拒绝")
+		}
+
+		if err := ResolveReports(content.ShortID, "admin", ReportDecisionUphold, ""); err != nil {
+			t.Fatalf("ResolveReports: %v", err)
+		}
+		DB.First(&content, content.ID)
+		if content.IsPublic || !content.Locked {
+			t.Fatalf("举报成立后 is_public=%v locked=%v", content.IsPublic, content.Locked)
+		}
+
+		// 作者不能重新公开被锁定的内容
+		content.IsPublic = true
+		if err := UpdateContent(&content, "alice"); !errors.Is(err, ErrContentLocked) {
+			t.Errorf("重新公开被锁定的内容返回 %v", err)
+		}
+	})
+}
+
+func TestUnpublishContentUnderReview(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T) {
+		content := createTestContent(t, models.Content{ShortID: "hidden", Type: "text", Data: "spam", Source: "alice", UnderReview: true})
+
+		if err := UnpublishContent(content.ShortID, "admin", ""); err != nil {
+			t.Fatalf("锁定审核中的内容: %v", err)
+		}
+		DB.First(&content, content.ID)
+		if content.IsPublic || content.UnderReview || !content.Locked {
+			t.Fatalf("锁定后 is_public=%v under_review=%v locked=%v", content.IsPublic, content.UnderReview, content.Locked)
+		}
+		if err := UnpublishContent(content.ShortID, "admin", ""); err == nil {
+			t.Error("重复锁定应返回错误")
+		}
+	})
+}
+
+func TestUnpublishCollection(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T) {
+		collection := models.Collection{Title: "spam", Source: "alice", IsPublic: true}
+		if err := CreateCollection(&collection, nil); err != nil {
+			t.Fatalf("CreateCollection: %v", err)
+		}
+
+		if err := UnpublishCollection(collection.ShortID, "admin", ""); err != nil {
+			t.Fatalf("UnpublishCollection: %v", err)
+		}
+		collection, _ = LoadCollection(collection.ShortID)
+		if collection.IsPublic || !collection.Locked {
+			t.Fatalf("取消公开后 is_public=%v locked=%v", collection.IsPublic, collection.Locked)
+		}
+
+		// 创建者不能重新公开被锁定的合集
+		collection.IsPublic = true
+		if err := UpdateCollection(&collection); !errors.Is(err, ErrCollectionLocked) {
+			t.Errorf("重新公开被锁定的合集返回 %v", err)
+		}
+
+		if err := UnlockCollection(collection.ShortID, "admin", ""); err != nil {
+			t.Fatalf("UnlockCollection: %v", err)
+		}
+		collection, _ = LoadCollection(collection.ShortID)
+		collection.IsPublic = true
+		if err := UpdateCollection(&collection); err != nil {
+			t.Errorf("解除锁定后重新公开: %v", err)
+		}
+	})
+}
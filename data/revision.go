@@ -0,0 +1,156 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sharesth/models"
+)
+
+// createRevision 为内容的当前状态创建一个新版本
+func createRevision(tx *gorm.DB, content models.Content, editor string, note string, createdAt time.Time) error {
+	var latest int
+	if err := tx.Model(&models.ContentRevision{}).
+		Where("content_id = ?", content.ID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&latest).Error; err != nil {
+		return fmt.Errorf("查询最新版本号失败: %v", err)
+	}
+
+	revision := models.ContentRevision{
+		ContentID: content.ID,
+		Revision:  latest + 1,
+		Title:     content.Title,
+		Data:      content.Data,
+		IsPublic:  content.IsPublic,
+		Editor:    editor,
+		Note:      note,
+		CreatedAt: createdAt,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return fmt.Errorf("保存历史版本失败: %v", err)
+	}
+
+	return nil
+}
+
+// ensureInitialRevision 为功能上线前创建、尚无任何版本的内容补记当前状态作为第一个版本
+func ensureInitialRevision(tx *gorm.DB, contentID uint) error {
+	var count int64
+	tx.Model(&models.ContentRevision{}).Where("content_id = ?", contentID).Count(&count)
+	if count > 0 {
+		return nil
+	}
+
+	var current models.Content
+	if err := tx.First(&current, contentID).Error; err != nil {
+		return fmt.Errorf("加载内容失败: %v", err)
+	}
+
+	return createRevision(tx, current, current.Source, "", current.UpdateTime)
+}
+
+// FindContentRevisions 查找内容的所有版本（不含内容数据），按版本号倒序
+func FindContentRevisions(contentID uint) []map[string]interface{} {
+	var revisions []struct {
+		Revision  int
+		Title     string
+		IsPublic  bool
+		Editor    string
+		Note      string
+		Size      int
+		CreatedAt time.Time
+	}
+	DB.Model(&models.ContentRevision{}).
+		Select("revision, title, is_public, editor, note, LENGTH(data) AS size, created_at").
+		Where("content_id = ?", contentID).
+		Order("revision DESC").
+		Scan(&revisions)
+
+	results := make([]map[string]interface{}, 0)
+	for _, revision := range revisions {
+		results = append(results, map[string]interface{}{
+			"revision":   revision.Revision,
+			"title":      revision.Title,
+			"is_public":  revision.IsPublic,
+			"editor":     revision.Editor,
+			"note":       revision.Note,
+			"size":       revision.Size,
+			"created_at": revision.CreatedAt,
+		})
+	}
+
+	return results
+}
+
+// LoadContentRevision 加载内容的指定版本
+func LoadContentRevision(contentID uint, revision int) (models.ContentRevision, error) {
+	var result models.ContentRevision
+	if err := DB.Where("content_id = ? AND revision = ?", contentID, revision).First(&result).Error; err != nil {
+		return models.ContentRevision{}, fmt.Errorf("版本不存在: %v", err)
+	}
+
+	return result, nil
+}
+
+// RestoreContentRevision 将内容恢复到指定版本，恢复操作本身会生成一个新版本而不是改写历史
+func RestoreContentRevision(content *models.Content, revision int, editor string) error {
+	target, err := LoadContentRevision(content.ID, revision)
+	if err != nil {
+		return err
+	}
+
+	content.Title = target.Title
+	content.Data = target.Data
+	content.IsPublic = target.IsPublic
+	content.UpdateTime = time.Now()
+
+	return saveContentWithRevision(content, editor, fmt.Sprintf("恢复自版本%d", revision))
+}
+
+// saveContentWithRevision 在同一事务中保存内容并记录新版本
+func saveContentWithRevision(content *models.Content, editor string, note string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		return saveContentRevisionTx(tx, content, editor, note)
+	})
+}
+
+// saveContentRevisionTx 在事务中保存内容，需要时记录新版本
+func saveContentRevisionTx(tx *gorm.DB, content *models.Content, editor string, note string) error {
+	// 等待举报审核或被管理员锁定的内容不能重新公开
+	if content.UnderReview && content.IsPublic {
+		return ErrContentUnderReview
+	}
+	if content.Locked && content.IsPublic {
+		return ErrContentLocked
+	}
+
+	if err := ensureInitialRevision(tx, content.ID); err != nil {
+		return err
+	}
+
+	// 标签通过 SetContentTags 单独维护，保存内容时不写关联
+	if err := tx.Omit(clause.Associations).Save(content).Error; err != nil {
+		return fmt.Errorf("更新内容失败: %v", err)
+	}
+	if err := addUploadReferences(tx, content.ID, content.Type, content.Data); err != nil {
+		return err
+	}
+
+	// 只修改了有效期、密码等设置时不产生新版本
+	var latest models.ContentRevision
+	tx.Where("content_id = ?", content.ID).Order("revision DESC").First(&latest)
+	if note == "" && latest.Title == content.Title && latest.Data == content.Data && latest.IsPublic == content.IsPublic {
+		return nil
+	}
+
+	return createRevision(tx, *content, editor, note, content.UpdateTime)
+}
+
+// deleteContentRevisions 删除内容的所有历史版本
+func deleteContentRevisions(tx *gorm.DB, contentID uint) error {
+	return tx.Where("content_id = ?", contentID).Delete(&models.ContentRevision{}).Error
+}
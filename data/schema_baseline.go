@@ -0,0 +1,214 @@
+package data
+
+import (
+	"time"
+)
+
+// 以下结构体是引入版本化迁移时数据库结构的快照，仅供迁移 0001_baseline 使用
+// 与 models 中的结构体分开定义，之后修改 models 不会改变已发布的迁移，结构变化需要追加新的迁移
+
+type baselineContent struct {
+	ID          uint   `gorm:"primaryKey"`
+	ShortID     string `gorm:"type:varchar(15);uniqueIndex"`
+	Type        string `gorm:"type:varchar(10)"`
+	Data        string
+	Source      string     `gorm:"type:varchar(10);index"`
+	CreateTime  time.Time  `gorm:"index"`
+	UpdateTime  time.Time  `gorm:"index"`
+	Title       string     `gorm:"type:varchar(255)"`
+	IsPublic    bool       `gorm:"default:false"`
+	ExpiresAt   *time.Time `gorm:"index"`
+	MaxViews    int        `gorm:"default:0"`
+	ViewCount   int        `gorm:"default:0"`
+	Password    string     `gorm:"type:varchar(255)"`
+	FileName    string     `gorm:"type:varchar(255)"`
+	MimeType    string     `gorm:"type:varchar(100)"`
+	FileSize    int64      `gorm:"default:0"`
+	Language    string     `gorm:"type:varchar(32)"`
+	UnderReview bool       `gorm:"default:false;index"`
+}
+
+func (baselineContent) TableName() string { return "contents" }
+
+type baselineFileMD5 struct {
+	ID        uint   `gorm:"primaryKey"`
+	MD5Hash   string `gorm:"type:varchar(32);uniqueIndex"`
+	FilePath  string `gorm:"type:varchar(255)"`
+	CreatedAt time.Time
+}
+
+func (baselineFileMD5) TableName() string { return "file_md5s" }
+
+type baselineUserFingerprint struct {
+	ID          uint   `gorm:"primaryKey"`
+	BrowserHash string `gorm:"type:varchar(64);uniqueIndex"`
+	UserID      string `gorm:"type:varchar(10)"`
+	BrowserInfo string `gorm:"type:text"`
+	CreatedAt   time.Time
+	LastSeenAt  time.Time
+}
+
+func (baselineUserFingerprint) TableName() string { return "user_fingerprints" }
+
+type baselineUser struct {
+	ID           uint   `gorm:"primaryKey"`
+	Username     string `gorm:"type:varchar(32);uniqueIndex"`
+	PasswordHash string `gorm:"type:varchar(255)"`
+	UserID       string `gorm:"type:varchar(10);uniqueIndex"`
+	CreatedAt    time.Time
+	LastLoginAt  time.Time
+}
+
+func (baselineUser) TableName() string { return "users" }
+
+type baselineSession struct {
+	ID        uint   `gorm:"primaryKey"`
+	TokenHash string `gorm:"type:varchar(64);uniqueIndex"`
+	AccountID uint   `gorm:"index"`
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+func (baselineSession) TableName() string { return "sessions" }
+
+type baselineClaimCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	CodeHash  string `gorm:"type:varchar(64);uniqueIndex"`
+	AccountID uint   `gorm:"index"`
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+func (baselineClaimCode) TableName() string { return "claim_codes" }
+
+type baselineIdentityMerge struct {
+	ID           uint   `gorm:"primaryKey"`
+	AccountID    uint   `gorm:"index"`
+	FromUserID   string `gorm:"type:varchar(10)"`
+	ToUserID     string `gorm:"type:varchar(10)"`
+	ContentCount int64
+	BrowserInfo  string `gorm:"type:text"`
+	CreatedAt    time.Time
+}
+
+func (baselineIdentityMerge) TableName() string { return "identity_merges" }
+
+type baselineAPIToken struct {
+	ID         uint   `gorm:"primaryKey"`
+	AccountID  uint   `gorm:"index"`
+	Name       string `gorm:"type:varchar(64)"`
+	TokenHash  string `gorm:"type:varchar(64);uniqueIndex"`
+	Prefix     string `gorm:"type:varchar(16)"`
+	Scopes     string `gorm:"type:varchar(64)"`
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+func (baselineAPIToken) TableName() string { return "api_tokens" }
+
+type baselineContentRevision struct {
+	ID        uint   `gorm:"primaryKey"`
+	ContentID uint   `gorm:"uniqueIndex:idx_content_revision"`
+	Revision  int    `gorm:"uniqueIndex:idx_content_revision"`
+	Title     string `gorm:"type:varchar(255)"`
+	Data      string
+	IsPublic  bool
+	Editor    string `gorm:"type:varchar(10)"`
+	Note      string `gorm:"type:varchar(64)"`
+	CreatedAt time.Time
+}
+
+func (baselineContentRevision) TableName() string { return "content_revisions" }
+
+type baselineUploadReference struct {
+	ID        uint   `gorm:"primaryKey"`
+	FilePath  string `gorm:"type:varchar(255);uniqueIndex:idx_upload_reference"`
+	ContentID uint   `gorm:"uniqueIndex:idx_upload_reference;index"`
+	CreatedAt time.Time
+}
+
+func (baselineUploadReference) TableName() string { return "upload_references" }
+
+type baselineTag struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"type:varchar(32);uniqueIndex"`
+	CreatedAt time.Time
+}
+
+func (baselineTag) TableName() string { return "tags" }
+
+// baselineContentTag 内容与标签的关联表，对应 models.Content.Tags 的 many2many 关系
+type baselineContentTag struct {
+	ContentID uint `gorm:"primaryKey"`
+	TagID     uint `gorm:"primaryKey"`
+}
+
+func (baselineContentTag) TableName() string { return "content_tags" }
+
+type baselineCollection struct {
+	ID          uint   `gorm:"primaryKey"`
+	ShortID     string `gorm:"type:varchar(15);uniqueIndex"`
+	Title       string `gorm:"type:varchar(255)"`
+	Description string `gorm:"type:text"`
+	Source      string `gorm:"type:varchar(10);index"`
+	IsPublic    bool   `gorm:"default:false"`
+	CreateTime  time.Time
+	UpdateTime  time.Time
+}
+
+func (baselineCollection) TableName() string { return "collections" }
+
+type baselineCollectionItem struct {
+	ID           uint `gorm:"primaryKey"`
+	CollectionID uint `gorm:"uniqueIndex:idx_collection_item"`
+	ContentID    uint `gorm:"uniqueIndex:idx_collection_item;index"`
+	Position     int
+	CreatedAt    time.Time
+}
+
+func (baselineCollectionItem) TableName() string { return "collection_items" }
+
+type baselineBan struct {
+	ID        uint   `gorm:"primaryKey"`
+	Source    string `gorm:"type:varchar(10);uniqueIndex"`
+	Reason    string `gorm:"type:varchar(255)"`
+	CreatedBy string `gorm:"type:varchar(64)"`
+	CreatedAt time.Time
+}
+
+func (baselineBan) TableName() string { return "bans" }
+
+type baselineAuditLog struct {
+	ID         uint      `gorm:"primaryKey"`
+	Actor      string    `gorm:"type:varchar(64);index"`
+	Action     string    `gorm:"type:varchar(32);index"`
+	TargetType string    `gorm:"type:varchar(16)"`
+	TargetID   string    `gorm:"type:varchar(64);index"`
+	Detail     string    `gorm:"type:text"`
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+func (baselineAuditLog) TableName() string { return "audit_logs" }
+
+type baselineReport struct {
+	ID         uint   `gorm:"primaryKey"`
+	ContentID  uint   `gorm:"index"`
+	ShortID    string `gorm:"type:varchar(15);index"`
+	Category   string `gorm:"type:varchar(16)"`
+	Detail     string `gorm:"type:text"`
+	Reporter   string `gorm:"type:varchar(10);index"`
+	Status     string `gorm:"type:varchar(16);index"`
+	ResolvedBy string `gorm:"type:varchar(64)"`
+	ResolvedAt *time.Time
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+func (baselineReport) TableName() string { return "reports" }
+
+// baselineTables 基线结构中的所有表，按创建顺序排列
+var baselineTables = []interface{}{
+	&baselineContent{}, &baselineFileMD5{}, &baselineUserFingerprint{}, &baselineUser{}, &baselineSession{},
+	&baselineClaimCode{}, &baselineIdentityMerge{}, &baselineAPIToken{}, &baselineContentRevision{},
+	&baselineUploadReference{}, &baselineTag{}, &baselineContentTag{}, &baselineCollection{},
+	&baselineCollectionItem{}, &baselineBan{}, &baselineAuditLog{}, &baselineReport{},
+}
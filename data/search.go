@@ -0,0 +1,365 @@
+package data
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sharesth/models"
+)
+
+// 全文搜索相关常量
+const (
+	// 全文索引表名
+	searchIndexTable = "contents_fts"
+	// 摘要中标记匹配词的起止符，转义HTML后再替换为 <mark> 标签
+	snippetMatchStart = "\x02"
+	snippetMatchEnd   = "\x03"
+	// 摘要包含的词数
+	snippetTokens = 24
+	// 标题匹配在相关度排序中的权重，正文为1
+	titleRankWeight = 5.0
+)
+
+// 全文索引的实现方式
+const (
+	searchIndexNone     = ""         // 无全文索引，只按标题模糊匹配
+	searchIndexFTS5     = "fts5"     // SQLite FTS5虚拟表
+	searchIndexTSVector = "tsvector" // PostgreSQL tsvector表达式索引
+)
+
+// PostgreSQL全文索引名
+const postgresSearchIndex = "idx_contents_search"
+
+// searchIndex 当前使用的全文索引，SQLite未编译FTS5或数据库不支持时退回标题模糊匹配
+var searchIndex = searchIndexNone
+
+// searchIndexTriggerNames 同步全文索引的触发器名，与 searchIndexTriggers 对应
+var searchIndexTriggerNames = []string{"contents_fts_insert", "contents_fts_update", "contents_fts_delete"}
+
+// searchIndexTriggers 在内容增删改时同步全文索引的触发器
+// 只索引未设置访问密码和访问次数限制的文本、Markdown和代码内容的正文，其他内容只索引标题
+var searchIndexTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS contents_fts_insert AFTER INSERT ON contents BEGIN
+		INSERT INTO contents_fts(rowid, title, data) VALUES (new.id, new.title, ` + indexedData("new") + `);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS contents_fts_update AFTER UPDATE OF title, data, type, password, max_views ON contents BEGIN
+		DELETE FROM contents_fts WHERE rowid = old.id;
+		INSERT INTO contents_fts(rowid, title, data) VALUES (new.id, new.title, ` + indexedData("new") + `);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS contents_fts_delete AFTER DELETE ON contents BEGIN
+		DELETE FROM contents_fts WHERE rowid = old.id;
+	END`,
+}
+
+// indexedData 返回需要写入全文索引的正文表达式
+// 与 Content.HidesPreview 一致，设置了访问密码或访问次数限制的内容不索引正文，否则搜索摘要和前缀、短语查询可以不计访问地获取正文
+func indexedData(row string) string {
+	return fmt.Sprintf("CASE WHEN %[1]s.type IN ('text', 'markdown', 'code') AND COALESCE(%[1]s.password, '') = '' AND COALESCE(%[1]s.max_views, 0) = 0 THEN %[1]s.data ELSE '' END", row)
+}
+
+// initSearchIndex 根据数据库中已建立的全文索引选择搜索方式，全文索引由迁移 add_search_index 创建
+func initSearchIndex() error {
+	searchIndex = searchIndexNone
+
+	switch DB.Dialector.Name() {
+	case "sqlite":
+		available := fts5Available(DB)
+		if !DB.Migrator().HasTable(searchIndexTable) {
+			if available {
+				log.Printf("数据库没有全文索引，搜索仅匹配标题（执行迁移 add_search_index 时程序不支持FTS5，撤销并重新执行该迁移即可建立索引）")
+			} else {
+				log.Printf("数据库没有全文索引，搜索仅匹配标题（请使用 -tags sqlite_fts5 构建）")
+			}
+			return nil
+		}
+		// 全文索引表的触发器在内容增删改时写入索引，不支持FTS5时所有写入都会失败
+		if !available {
+			return fmt.Errorf("数据库包含FTS5全文索引，请使用 -tags sqlite_fts5 构建")
+		}
+		searchIndex = searchIndexFTS5
+	case "postgres":
+		if !DB.Migrator().HasIndex(&models.Content{}, postgresSearchIndex) {
+			log.Printf("数据库没有全文索引，搜索仅匹配标题")
+			return nil
+		}
+		searchIndex = searchIndexTSVector
+	default:
+		log.Printf("当前数据库不支持全文搜索，搜索仅匹配标题")
+	}
+	return nil
+}
+
+// fts5Available 返回SQLite是否编译了FTS5，默认编译的 go-sqlite3 不包含FTS5，需要使用 -tags sqlite_fts5 构建
+func fts5Available(db *gorm.DB) bool {
+	var used int
+	return db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&used).Error == nil && used == 1
+}
+
+// createSearchIndex 根据数据库类型创建全文索引，可以重复执行
+// SQLite创建FTS5全文索引表和同步触发器，首次创建时为已有内容建立索引，不支持FTS5时跳过
+// PostgreSQL在内容的全文向量表达式上创建GIN索引，索引随内容自动更新，无需触发器
+func createSearchIndex(tx *gorm.DB) error {
+	switch tx.Dialector.Name() {
+	case "sqlite":
+		if !fts5Available(tx) {
+			log.Printf("SQLite不支持FTS5，跳过创建全文索引（请使用 -tags sqlite_fts5 构建）")
+			return nil
+		}
+
+		created := !tx.Migrator().HasTable(searchIndexTable)
+		if created {
+			err := tx.Exec("CREATE VIRTUAL TABLE " + searchIndexTable + " USING fts5(title, data, tokenize = 'unicode61 remove_diacritics 2')").Error
+			if err != nil {
+				return fmt.Errorf("创建全文索引失败: %v", err)
+			}
+		}
+
+		for _, trigger := range searchIndexTriggers {
+			if err := tx.Exec(trigger).Error; err != nil {
+				return fmt.Errorf("创建全文索引触发器失败: %v", err)
+			}
+		}
+
+		if created {
+			err := tx.Exec("INSERT INTO " + searchIndexTable + "(rowid, title, data) SELECT id, title, " + indexedData("contents") + " FROM contents").Error
+			if err != nil {
+				return fmt.Errorf("建立全文索引失败: %v", err)
+			}
+		}
+	case "postgres":
+		err := tx.Exec("CREATE INDEX IF NOT EXISTS " + postgresSearchIndex + " ON contents USING GIN ((" + postgresSearchVector() + "))").Error
+		if err != nil {
+			return fmt.Errorf("创建全文索引失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// rebuildSearchIndex 按当前的索引定义重建已有的全文索引，数据库没有全文索引时不做处理
+func rebuildSearchIndex(tx *gorm.DB) error {
+	var exists bool
+	switch tx.Dialector.Name() {
+	case "sqlite":
+		exists = tx.Migrator().HasTable(searchIndexTable)
+	case "postgres":
+		exists = tx.Migrator().HasIndex("contents", postgresSearchIndex)
+	}
+	if !exists {
+		return nil
+	}
+
+	if err := dropSearchIndex(tx); err != nil {
+		return err
+	}
+	return createSearchIndex(tx)
+}
+
+// dropSearchIndex 删除 createSearchIndex 创建的全文索引和触发器，可以重复执行
+func dropSearchIndex(tx *gorm.DB) error {
+	var statements []string
+	switch tx.Dialector.Name() {
+	case "sqlite":
+		for _, trigger := range searchIndexTriggerNames {
+			statements = append(statements, "DROP TRIGGER IF EXISTS "+trigger)
+		}
+		statements = append(statements, "DROP TABLE IF EXISTS "+searchIndexTable)
+	case "postgres":
+		statements = append(statements, "DROP INDEX IF EXISTS "+postgresSearchIndex)
+	}
+
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return fmt.Errorf("删除全文索引失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// postgresSearchVector 返回内容的全文向量表达式，查询时必须使用相同的表达式才能命中索引
+// 使用 simple 配置，不做词干提取，与FTS5的 unicode61 分词行为接近
+func postgresSearchVector() string {
+	return "to_tsvector('simple', COALESCE(contents.title, '') || ' ' || " + indexedData("contents") + ")"
+}
+
+// postgresRankVector 返回用于相关度排序的全文向量表达式，标题为A权重，正文为D权重
+func postgresRankVector() string {
+	return "setweight(to_tsvector('simple', COALESCE(contents.title, '')), 'A') || setweight(to_tsvector('simple', " + indexedData("contents") + "), 'D')"
+}
+
+// postgresRankWeights 返回 ts_rank 的权重数组（依次为D、C、B、A），使标题匹配的权重为正文的 titleRankWeight 倍
+func postgresRankWeights() string {
+	body := 1 / titleRankWeight
+	return fmt.Sprintf("{%g, %g, %g, 1}", body, body, body)
+}
+
+// searchTerm 搜索输入中的一个词或短语
+type searchTerm struct {
+	text   string // 词或短语
+	prefix bool   // 是否前缀匹配
+}
+
+// parseSearchTerms 解析用户输入的搜索词
+// 支持 "完整短语" 和 前缀* 两种语法，其他符号都按普通文本处理
+func parseSearchTerms(query string) []searchTerm {
+	var terms []searchTerm
+	rest := strings.TrimSpace(query)
+
+	for rest != "" {
+		if rest[0] == '"' {
+			// 短语，缺少结束引号时取到末尾
+			phrase, after, _ := strings.Cut(rest[1:], `"`)
+			prefix := strings.HasPrefix(after, "*")
+			if prefix {
+				after = after[1:]
+			}
+			terms = appendSearchTerm(terms, phrase, prefix)
+			rest = strings.TrimSpace(after)
+			continue
+		}
+
+		word, after, _ := strings.Cut(rest, " ")
+		prefix := strings.HasSuffix(word, "*")
+		terms = appendSearchTerm(terms, strings.TrimRight(word, "*"), prefix)
+		rest = strings.TrimSpace(after)
+	}
+
+	return terms
+}
+
+// appendSearchTerm 追加一个非空的词或短语
+func appendSearchTerm(terms []searchTerm, text string, prefix bool) []searchTerm {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return terms
+	}
+	return append(terms, searchTerm{text: text, prefix: prefix})
+}
+
+// BuildSearchQuery 将用户输入转换为FTS5查询语句，多个词之间为并且关系
+func BuildSearchQuery(query string) string {
+	var parts []string
+	for _, term := range parseSearchTerms(query) {
+		quoted := `"` + strings.ReplaceAll(term.text, `"`, `""`) + `"`
+		if term.prefix {
+			quoted += "*"
+		}
+		parts = append(parts, quoted)
+	}
+	return strings.Join(parts, " ")
+}
+
+// BuildTSQuery 将用户输入转换为PostgreSQL的 tsquery 语句，多个词之间为并且关系
+// 短语中的词按顺序相邻匹配，前缀匹配作用于短语的最后一个词
+func BuildTSQuery(query string) string {
+	var parts []string
+	for _, term := range parseSearchTerms(query) {
+		words := strings.Fields(term.text)
+		for i, word := range words {
+			words[i] = "'" + strings.NewReplacer(`'`, `''`, `\`, `\\`).Replace(word) + "'"
+		}
+		if term.prefix {
+			words[len(words)-1] += ":*"
+		}
+		parts = append(parts, "("+strings.Join(words, " <-> ")+")")
+	}
+	return strings.Join(parts, " & ")
+}
+
+// buildIndexQuery 根据当前使用的全文索引转换用户输入，没有全文索引或输入为空时返回空字符串
+func buildIndexQuery(query string) string {
+	switch searchIndex {
+	case searchIndexFTS5:
+		return BuildSearchQuery(query)
+	case searchIndexTSVector:
+		return BuildTSQuery(query)
+	default:
+		return ""
+	}
+}
+
+// titleLikeCondition 返回 table 表的标题模糊匹配条件，PostgreSQL的 LIKE 区分大小写，改用 ILIKE 与其他数据库保持一致
+func titleLikeCondition(table string) string {
+	if DB.Dialector.Name() == "postgres" {
+		return table + ".title ILIKE ?"
+	}
+	return table + ".title LIKE ?"
+}
+
+// searchContents 为查询添加搜索条件
+// 启用全文索引时匹配标题和正文并按相关度排序，同时保留标题模糊匹配（分词无法切分中文）；否则只按标题模糊匹配
+func searchContents(db *gorm.DB, query string) *gorm.DB {
+	titleLike := "%" + query + "%"
+
+	match := buildIndexQuery(query)
+	if match == "" {
+		return db.Where(titleLikeCondition("contents"), titleLike).Order("contents.create_time DESC")
+	}
+
+	if searchIndex == searchIndexTSVector {
+		return db.Where("("+postgresSearchVector()+" @@ to_tsquery('simple', ?) OR "+titleLikeCondition("contents")+")", match, titleLike).
+			Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "ts_rank('" + postgresRankWeights() + "'::real[], " + postgresRankVector() + ", to_tsquery('simple', ?)) DESC, contents.create_time DESC",
+				Vars:               []interface{}{match},
+				WithoutParentheses: true,
+			}})
+	}
+
+	return db.Joins("LEFT JOIN (SELECT rowid, bm25("+searchIndexTable+", ?, 1.0) AS rank FROM "+searchIndexTable+" WHERE "+searchIndexTable+" MATCH ?) AS matches ON matches.rowid = contents.id", titleRankWeight, match).
+		Where("matches.rowid IS NOT NULL OR "+titleLikeCondition("contents"), titleLike).
+		Order("matches.rank IS NULL, matches.rank, contents.create_time DESC")
+}
+
+// findSearchSnippets 查询指定内容正文中匹配位置的摘要，返回内容ID到已转义HTML摘要的映射
+func findSearchSnippets(query string, contents []models.Content) map[uint]string {
+	snippets := make(map[uint]string)
+
+	match := buildIndexQuery(query)
+	if match == "" || len(contents) == 0 {
+		return snippets
+	}
+
+	ids := make([]uint, 0, len(contents))
+	for _, content := range contents {
+		ids = append(ids, content.ID)
+	}
+
+	var rows []struct {
+		ID      uint   `gorm:"column:id"`
+		Snippet string `gorm:"column:snippet"`
+	}
+	var err error
+	if searchIndex == searchIndexTSVector {
+		// ts_headline 在没有匹配时返回正文开头且不含标记，下面会过滤掉
+		options := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=%d, MinWords=%d`, snippetMatchStart, snippetMatchEnd, snippetTokens, snippetTokens/2)
+		err = DB.Raw("SELECT contents.id AS id, ts_headline('simple', "+indexedData("contents")+", to_tsquery('simple', ?), ?) AS snippet FROM contents WHERE contents.id IN ?",
+			match, options, ids).Scan(&rows).Error
+	} else {
+		err = DB.Raw("SELECT rowid AS id, snippet("+searchIndexTable+", 1, ?, ?, '…', ?) AS snippet FROM "+searchIndexTable+" WHERE "+searchIndexTable+" MATCH ? AND rowid IN ?",
+			snippetMatchStart, snippetMatchEnd, snippetTokens, match, ids).Scan(&rows).Error
+	}
+	if err != nil {
+		log.Printf("查询搜索摘要失败: %v", err)
+		return snippets
+	}
+
+	// 只在正文中确实有匹配时返回摘要，仅标题匹配的内容仍显示原有摘要
+	for _, row := range rows {
+		if strings.Contains(row.Snippet, snippetMatchStart) {
+			snippets[row.ID] = highlightSnippet(row.Snippet)
+		}
+	}
+
+	return snippets
+}
+
+// highlightSnippet 转义摘要中的HTML，并将匹配词标记为 <mark>
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, snippetMatchStart, "<mark>")
+	return strings.ReplaceAll(escaped, snippetMatchEnd, "</mark>")
+}
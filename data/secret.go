@@ -0,0 +1,61 @@
+package data
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"sharesth/config"
+)
+
+// 用于签名Cookie等数据的密钥
+var secretKey []byte
+
+// InitSecretKey 初始化签名密钥，优先使用配置的密钥，未配置时读取或生成密钥文件
+// 多实例部署时需要为所有实例配置相同的密钥
+func InitSecretKey(cfg config.SecurityConfig) error {
+	if cfg.SecretKey != "" {
+		secretKey = []byte(cfg.SecretKey)
+		return nil
+	}
+
+	// 读取已保存的密钥
+	if content, err := os.ReadFile(cfg.SecretKeyFile); err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(content)))
+		if err != nil || len(key) == 0 {
+			return fmt.Errorf("密钥文件格式错误: %s", cfg.SecretKeyFile)
+		}
+		secretKey = key
+		return nil
+	}
+
+	// 生成新密钥并保存
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("生成签名密钥失败: %v", err)
+	}
+	if err := os.WriteFile(cfg.SecretKeyFile, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		return fmt.Errorf("保存签名密钥失败: %v", err)
+	}
+	secretKey = key
+
+	log.Printf("已生成新的签名密钥: %s", cfg.SecretKeyFile)
+	return nil
+}
+
+// Sign 使用签名密钥计算消息的HMAC-SHA256签名
+func Sign(message string) string {
+	mac := hmac.New(sha256.New, secretKey)
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature 校验消息签名
+func VerifySignature(message string, signature string) bool {
+	return hmac.Equal([]byte(Sign(message)), []byte(signature))
+}
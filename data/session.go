@@ -0,0 +1,97 @@
+package data
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"sharesth/models"
+)
+
+// 会话相关常量
+const (
+	// 保存会话令牌的Cookie名称
+	SessionCookieName = "sharesth_session"
+	// 会话有效期（30天）
+	SessionExpiration = 30 * 24 * time.Hour
+)
+
+// hashToken 计算令牌的SHA-256哈希，数据库中只保存哈希值
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateToken 生成指定字节数的随机令牌（十六进制编码）
+func generateToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成随机令牌失败: %v", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// CreateSession 为账户创建新会话，返回令牌原文和过期时间
+func CreateSession(accountID uint) (string, time.Time, error) {
+	token, err := generateToken(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	session := models.Session{
+		TokenHash: hashToken(token),
+		AccountID: accountID,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(SessionExpiration),
+	}
+	if err := DB.Create(&session).Error; err != nil {
+		return "", time.Time{}, fmt.Errorf("保存会话失败: %v", err)
+	}
+
+	return token, session.ExpiresAt, nil
+}
+
+// FindUserBySessionToken 根据会话令牌查找已登录的账户
+func FindUserBySessionToken(token string) (models.User, bool) {
+	if token == "" {
+		return models.User{}, false
+	}
+
+	var session models.Session
+	result := DB.Where("token_hash = ? AND expires_at > ?", hashToken(token), time.Now()).First(&session)
+	if result.Error != nil {
+		return models.User{}, false
+	}
+
+	return FindUserByID(session.AccountID)
+}
+
+// DeleteSession 删除指定令牌对应的会话
+func DeleteSession(token string) error {
+	return DB.Where("token_hash = ?", hashToken(token)).Delete(&models.Session{}).Error
+}
+
+// DeleteExpiredSessions 清理已过期的会话
+func DeleteExpiredSessions() {
+	result := DB.Where("expires_at <= ?", time.Now()).Delete(&models.Session{})
+	if result.Error != nil {
+		log.Printf("清理过期会话失败: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("清理了 %d 个过期会话", result.RowsAffected)
+	}
+}
+
+// GetSessionUser 从请求的会话Cookie中获取已登录的账户
+func GetSessionUser(r *http.Request) (models.User, bool) {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return models.User{}, false
+	}
+
+	return FindUserBySessionToken(cookie.Value)
+}
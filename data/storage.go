@@ -0,0 +1,69 @@
+package data
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"sharesth/config"
+	"sharesth/storage"
+	"sharesth/utils"
+)
+
+// 上传文件访问相关常量
+const (
+	// 重定向到存储后端时签名URL的有效期
+	SignedURLExpiration = 15 * time.Minute
+)
+
+var (
+	// Store 上传文件的存储后端
+	Store storage.Storage
+	// RedirectUploads 访问上传文件时是否重定向到存储后端的签名URL，而不是由应用转发文件内容
+	RedirectUploads bool
+)
+
+// InitStorage 初始化上传文件的存储后端
+// backend 为 "s3" 时使用S3兼容存储，否则使用本地目录；内容中保存的路径始终以 uploads/ 开头，与存储位置无关
+func InitStorage(cfg config.StorageConfig) error {
+	switch cfg.Backend {
+	case "", "local":
+		local, err := storage.NewLocal(cfg.LocalDir)
+		if err != nil {
+			return err
+		}
+		Store = local
+		log.Printf("使用本地存储: %s", cfg.LocalDir)
+	case "s3":
+		s3, err := storage.NewS3(storage.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			Prefix:    cfg.S3.Prefix,
+		})
+		if err != nil {
+			return err
+		}
+		Store = s3
+		RedirectUploads = cfg.S3.Redirect
+		log.Printf("使用S3存储: %s", cfg.S3.Endpoint)
+	default:
+		return fmt.Errorf("不支持的存储后端: %s", cfg.Backend)
+	}
+
+	return nil
+}
+
+// UploadKey 将内容中保存的上传文件路径（如 "uploads/xxx.png"）转换为存储对象键
+func UploadKey(filePath string) string {
+	return strings.TrimPrefix(path.Clean("/"+filePath), "/"+utils.UploadsDir+"/")
+}
+
+// UploadPath 将存储对象键转换为内容中保存的上传文件路径
+func UploadPath(key string) string {
+	return path.Join(utils.UploadsDir, key)
+}
@@ -0,0 +1,153 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sharesth/models"
+)
+
+// 标签相关限制
+const (
+	// 每个内容最多的标签数
+	MaxTagsPerContent = 10
+	// 标签名的最大长度（字符数）
+	MaxTagLength = 32
+	// 列表中最多返回的标签统计数
+	MaxTagCounts = 50
+)
+
+// isTagSeparator 判断字符是否为标签分隔符，支持中英文逗号、顿号和空白
+func isTagSeparator(r rune) bool {
+	return r == ',' || r == '，' || r == '、' || unicode.IsSpace(r)
+}
+
+// NormalizeTag 将标签名统一为小写并去掉开头的 #，标签名只允许字母、数字和 -_.+#
+func NormalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimLeft(strings.TrimSpace(name), "#"))
+	if name == "" {
+		return "", fmt.Errorf("标签不能为空")
+	}
+	if utf8.RuneCountInString(name) > MaxTagLength {
+		return "", fmt.Errorf("标签 %s 过长，最多%d个字符", name, MaxTagLength)
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.+#", r) {
+			return "", fmt.Errorf("标签 %s 包含无效字符", name)
+		}
+	}
+	return name, nil
+}
+
+// ParseTags 解析逗号或空格分隔的标签列表，去除重复的标签
+func ParseTags(input string) ([]string, error) {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, field := range strings.FieldsFunc(input, isTagSeparator) {
+		if strings.Trim(field, "#") == "" {
+			continue
+		}
+		name, err := NormalizeTag(field)
+		if err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	if len(names) > MaxTagsPerContent {
+		return nil, fmt.Errorf("标签过多，每个内容最多%d个标签", MaxTagsPerContent)
+	}
+
+	return names, nil
+}
+
+// ResolveTags 根据标签名查找标签，不存在的标签会被创建，返回顺序与传入顺序一致
+func ResolveTags(names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	if len(names) == 0 {
+		return tags, nil
+	}
+
+	now := time.Now()
+	for _, name := range names {
+		tags = append(tags, models.Tag{Name: name, CreatedAt: now})
+	}
+	if err := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return nil, fmt.Errorf("创建标签失败: %v", err)
+	}
+
+	// 已存在的标签不会返回ID，重新查询一次
+	var existing []models.Tag
+	if err := DB.Where("name IN ?", names).Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	byName := make(map[string]models.Tag, len(existing))
+	for _, tag := range existing {
+		byName[tag.Name] = tag
+	}
+
+	tags = tags[:0]
+	for _, name := range names {
+		if tag, ok := byName[name]; ok {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
+
+// SetContentTags 将内容的标签替换为 content.Tags
+func SetContentTags(content *models.Content) error {
+	if err := DB.Model(content).Association("Tags").Replace(content.Tags); err != nil {
+		return fmt.Errorf("更新内容标签失败: %v", err)
+	}
+	return nil
+}
+
+// deleteContentTags 删除内容与标签的关联
+func deleteContentTags(tx *gorm.DB, contentID uint) error {
+	return tx.Exec("DELETE FROM content_tags WHERE content_id = ?", contentID).Error
+}
+
+// withTag 只保留带有指定标签的内容
+func withTag(tag string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("contents.id IN (SELECT content_tags.content_id FROM content_tags JOIN tags ON tags.id = content_tags.tag_id WHERE tags.name = ?)", tag)
+	}
+}
+
+// countTags 统计满足条件的内容中各标签的数量，只返回数量最多的 MaxTagCounts 个标签
+func countTags(scopes ...func(*gorm.DB) *gorm.DB) map[string]int64 {
+	var tagStats []struct {
+		Name  string `gorm:"column:name"`
+		Count int64  `gorm:"column:count"`
+	}
+
+	DB.Table("content_tags").
+		Select("tags.name AS name, count(*) AS count").
+		Joins("JOIN tags ON tags.id = content_tags.tag_id").
+		Joins("JOIN contents ON contents.id = content_tags.content_id").
+		Scopes(scopes...).
+		Group("tags.name").
+		Order("count DESC, tags.name").
+		Limit(MaxTagCounts).
+		Scan(&tagStats)
+
+	tagCounts := make(map[string]int64, len(tagStats))
+	for _, stat := range tagStats {
+		tagCounts[stat.Name] = stat.Count
+	}
+
+	return tagCounts
+}
@@ -0,0 +1,148 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"sharesth/models"
+)
+
+// 上传文件回收相关常量
+const (
+	// 定期回收的间隔
+	UploadGCInterval = 6 * time.Hour
+	// 新上传的文件在此时间内即使没有引用也不回收，
+	// 避免删除编辑器中刚上传、所在内容尚未保存的图片
+	UploadGCGracePeriod = time.Hour
+)
+
+// UploadGCReport 一次上传文件回收的结果
+type UploadGCReport struct {
+	DryRun            bool     `json:"dry_run"`            // 是否只报告不删除
+	ScannedFiles      int      `json:"scanned_files"`      // 存储中的文件总数
+	ReferencedFiles   int      `json:"referenced_files"`   // 仍被引用的文件数
+	RecentFiles       int      `json:"recent_files"`       // 处于保护期内的未引用文件数
+	UnreferencedFiles []string `json:"unreferenced_files"` // 无引用的文件
+	UnreferencedBytes int64    `json:"unreferenced_bytes"` // 无引用文件的总大小
+	StaleMD5Rows      []string `json:"stale_md5_rows"`     // 对应文件已不存在的MD5索引
+	RemovedFiles      int      `json:"removed_files"`      // 实际删除的文件数
+	RemovedMD5Rows    int      `json:"removed_md5_rows"`   // 实际删除的MD5索引数
+}
+
+// StartUploadGC 启动后台协程，定期回收无引用的上传文件
+func StartUploadGC(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := CollectUploadGarbage(false); err != nil {
+				log.Printf("回收上传文件失败: %v", err)
+			}
+		}
+	}()
+}
+
+// CollectUploadGarbage 找出没有任何内容引用的上传文件和失效的MD5索引，dryRun 为 false 时将其删除
+func CollectUploadGarbage(dryRun bool) (UploadGCReport, error) {
+	report := UploadGCReport{
+		DryRun:            dryRun,
+		UnreferencedFiles: make([]string, 0),
+		StaleMD5Rows:      make([]string, 0),
+	}
+	ctx := context.Background()
+
+	objects, err := Store.List(ctx, "")
+	if err != nil {
+		return report, err
+	}
+
+	var referencedPaths []string
+	if err := DB.Model(&models.UploadReference{}).Distinct("file_path").Pluck("file_path", &referencedPaths).Error; err != nil {
+		return report, fmt.Errorf("查询文件引用失败: %v", err)
+	}
+	referenced := make(map[string]bool, len(referencedPaths))
+	for _, filePath := range referencedPaths {
+		referenced[filePath] = true
+	}
+
+	// 重复上传时只刷新MD5索引中的上传时间，不改变对象的修改时间
+	var fileMD5s []models.FileMD5
+	DB.Find(&fileMD5s)
+	uploadedAt := make(map[string]time.Time, len(fileMD5s))
+	for _, fileMD5 := range fileMD5s {
+		if fileMD5.UploadedAt != nil && fileMD5.UploadedAt.After(uploadedAt[fileMD5.FilePath]) {
+			uploadedAt[fileMD5.FilePath] = *fileMD5.UploadedAt
+		}
+	}
+
+	// 检查存储中的每个文件是否仍被引用
+	existing := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		filePath := UploadPath(object.Key)
+		existing[filePath] = true
+		report.ScannedFiles++
+
+		if referenced[filePath] {
+			report.ReferencedFiles++
+			continue
+		}
+		if time.Since(object.ModTime) < UploadGCGracePeriod || time.Since(uploadedAt[filePath]) < UploadGCGracePeriod {
+			report.RecentFiles++
+			continue
+		}
+
+		report.UnreferencedFiles = append(report.UnreferencedFiles, filePath)
+		report.UnreferencedBytes += object.Size
+	}
+
+	// 检查MD5索引指向的文件是否还存在
+	for _, fileMD5 := range fileMD5s {
+		if !existing[fileMD5.FilePath] {
+			report.StaleMD5Rows = append(report.StaleMD5Rows, fileMD5.FilePath)
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	for _, filePath := range report.UnreferencedFiles {
+		// 扫描期间文件可能被新保存的内容引用或被重复上传刷新了上传时间，删除前再次确认
+		if CountUploadReferences(filePath) > 0 || recentlyUploaded(ctx, filePath) {
+			continue
+		}
+		if err := Store.Delete(ctx, UploadKey(filePath)); err != nil {
+			log.Printf("删除上传文件失败: %s, %v", filePath, err)
+			continue
+		}
+		report.RemovedFiles++
+		report.RemovedMD5Rows += int(DB.Where("file_path = ?", filePath).Delete(&models.FileMD5{}).RowsAffected)
+	}
+
+	for _, filePath := range report.StaleMD5Rows {
+		report.RemovedMD5Rows += int(DB.Where("file_path = ?", filePath).Delete(&models.FileMD5{}).RowsAffected)
+	}
+
+	if report.RemovedFiles > 0 || report.RemovedMD5Rows > 0 {
+		log.Printf("回收了 %d 个无引用的上传文件，删除 %d 条MD5索引", report.RemovedFiles, report.RemovedMD5Rows)
+	}
+	return report, nil
+}
+
+// recentlyUploaded 判断文件是否处于回收保护期：对象刚写入，或重复上传时刷新了MD5索引中的上传时间
+// 无法获取对象信息时也视为处于保护期，不删除
+func recentlyUploaded(ctx context.Context, filePath string) bool {
+	info, err := Store.Stat(ctx, UploadKey(filePath))
+	if err != nil || time.Since(info.ModTime) < UploadGCGracePeriod {
+		return true
+	}
+
+	var recent int64
+	DB.Model(&models.FileMD5{}).
+		Where("file_path = ? AND uploaded_at > ?", filePath, time.Now().Add(-UploadGCGracePeriod)).
+		Count(&recent)
+	return recent > 0
+}
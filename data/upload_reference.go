@@ -0,0 +1,130 @@
+package data
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sharesth/models"
+	"sharesth/utils"
+)
+
+// uploadLinkPattern 匹配文本中引用上传文件的链接，如 ![](/uploads/xxx.png) 或 <img src="/uploads/xxx.png">
+var uploadLinkPattern = regexp.MustCompile(`/` + utils.UploadsDir + `/[^\s()\[\]"'<>?#]+`)
+
+// ExtractUploadPaths 提取内容数据中引用的上传文件路径（如 "uploads/xxx.png"），结果已去重
+func ExtractUploadPaths(contentType string, contentData string) []string {
+	var candidates []string
+	switch contentType {
+	case "image", "file":
+		candidates = []string{contentData}
+	case "markdown":
+		candidates = uploadLinkPattern.FindAllString(contentData, -1)
+	}
+
+	paths := make([]string, 0, len(candidates))
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		if unescaped, err := url.PathUnescape(candidate); err == nil {
+			candidate = unescaped
+		}
+
+		// 规范化路径，忽略上传目录之外的路径
+		filePath := strings.TrimPrefix(path.Clean("/"+candidate), "/")
+		if !strings.HasPrefix(filePath, utils.UploadsDir+"/") || seen[filePath] {
+			continue
+		}
+		seen[filePath] = true
+		paths = append(paths, filePath)
+	}
+
+	return paths
+}
+
+// addUploadReferences 记录内容数据引用的上传文件，已存在的引用会被忽略
+// 内容修改后不删除旧的引用，因为历史版本仍可能被恢复
+func addUploadReferences(tx *gorm.DB, contentID uint, contentType string, contentData string) error {
+	paths := ExtractUploadPaths(contentType, contentData)
+	if len(paths) == 0 {
+		return nil
+	}
+
+	references := make([]models.UploadReference, 0, len(paths))
+	for _, filePath := range paths {
+		references = append(references, models.UploadReference{
+			FilePath:  filePath,
+			ContentID: contentID,
+			CreatedAt: time.Now(),
+		})
+	}
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&references).Error; err != nil {
+		return fmt.Errorf("保存文件引用失败: %v", err)
+	}
+
+	return nil
+}
+
+// deleteContentUploadReferences 删除内容的所有文件引用，返回被引用过的文件路径
+func deleteContentUploadReferences(tx *gorm.DB, contentID uint) ([]string, error) {
+	var paths []string
+	if err := tx.Model(&models.UploadReference{}).Where("content_id = ?", contentID).Pluck("file_path", &paths).Error; err != nil {
+		return nil, fmt.Errorf("查询文件引用失败: %v", err)
+	}
+
+	if err := tx.Where("content_id = ?", contentID).Delete(&models.UploadReference{}).Error; err != nil {
+		return nil, fmt.Errorf("删除文件引用失败: %v", err)
+	}
+
+	return paths, nil
+}
+
+// releaseUploadedFiles 删除不再被引用的上传文件，需在删除文件引用的事务提交后调用
+func releaseUploadedFiles(paths []string) {
+	for _, filePath := range paths {
+		ReleaseUploadedFile(filePath)
+	}
+}
+
+// CountUploadReferences 统计引用指定上传文件的内容数量
+func CountUploadReferences(filePath string) int64 {
+	var count int64
+	DB.Model(&models.UploadReference{}).Where("file_path = ?", filePath).Count(&count)
+	return count
+}
+
+// backfillUploadReferences 根据现有内容及其历史版本重建文件引用，用于引用表首次创建时
+func backfillUploadReferences(db *gorm.DB) error {
+	var contents []models.Content
+	result := db.Select("id", "type", "data").
+		Where("type IN ?", []string{"image", "markdown"}).
+		FindInBatches(&contents, 100, func(batchDB *gorm.DB, batch int) error {
+			for _, content := range contents {
+				if err := addUploadReferences(db, content.ID, content.Type, content.Data); err != nil {
+					return err
+				}
+
+				var revisions []string
+				db.Model(&models.ContentRevision{}).Where("content_id = ?", content.ID).Pluck("data", &revisions)
+				for _, revision := range revisions {
+					if err := addUploadReferences(db, content.ID, content.Type, revision); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	if result.Error != nil {
+		return fmt.Errorf("重建文件引用失败: %v", result.Error)
+	}
+
+	log.Printf("已根据 %d 条内容重建文件引用", result.RowsAffected)
+	return nil
+}
@@ -9,12 +9,12 @@ import (
 
 // FindUserIDByBrowserHash 根据浏览器哈希查找用户ID
 func FindUserIDByBrowserHash(browserHash string) (string, bool) {
-	// 先从Redis缓存查询
-	if userID, found := GetUserIDFromRedis(browserHash); found {
+	// 先从缓存查询
+	if userID, found := GetCachedUserID(browserHash); found {
 		return userID, true
 	}
 
-	// Redis中没有，查询数据库
+	// 缓存中没有，查询数据库
 	var fingerprint models.UserFingerprint
 	result := DB.Where("browser_hash = ?", browserHash).First(&fingerprint)
 	if result.Error != nil {
@@ -24,8 +24,8 @@ func FindUserIDByBrowserHash(browserHash string) (string, bool) {
 	// 更新最近访问时间
 	DB.Model(&fingerprint).Update("last_seen_at", time.Now())
 
-	// 将结果写入Redis缓存
-	SaveUserIDToRedis(browserHash, fingerprint.UserID)
+	// 将结果写入缓存
+	CacheUserID(browserHash, fingerprint.UserID)
 
 	return fingerprint.UserID, true
 }
@@ -56,14 +56,20 @@ func SaveUserFingerprint(browserHash string, userID string, browserInfo string)
 		err = DB.Create(&fingerprint).Error
 	}
 
-	// 无论是更新还是创建，都保存到Redis缓存
+	// 无论是更新还是创建，都保存到缓存
 	if err == nil {
-		SaveUserIDToRedis(browserHash, userID)
+		CacheUserID(browserHash, userID)
 	}
 
 	return err
 }
 
+// DeleteUserFingerprint 删除浏览器指纹记录及其缓存
+func DeleteUserFingerprint(browserHash string) error {
+	DeleteCachedUserID(browserHash)
+	return DB.Where("browser_hash = ?", browserHash).Delete(&models.UserFingerprint{}).Error
+}
+
 // GetAllAllocatedUserIDs 获取所有已分配的用户ID
 func GetAllAllocatedUserIDs() map[string]bool {
 	result := make(map[string]bool)
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+)
+
+// 管理后台列表每页的最大条数
+const maxAdminPerPage = 100
+
+// UploadGCReportHandler 报告无引用的上传文件和失效的MD5索引，不做删除
+func UploadGCReportHandler(c *gin.Context) {
+	report, err := data.CollectUploadGarbage(true)
+	if err != nil {
+		log.Printf("检查上传文件失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "检查上传文件失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// UploadGCHandler 删除无引用的上传文件和失效的MD5索引
+func UploadGCHandler(c *gin.Context) {
+	report, err := data.CollectUploadGarbage(false)
+	if err != nil {
+		log.Printf("回收上传文件失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "回收上传文件失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parsePageParams 解析分页参数，每页条数不超过 maxAdminPerPage
+func parsePageParams(c *gin.Context) (int, int) {
+	page := 1
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+
+	perPage := 20
+	if pp, err := strconv.Atoi(c.Query("per_page")); err == nil && pp > 0 {
+		perPage = pp
+	}
+	if perPage > maxAdminPerPage {
+		perPage = maxAdminPerPage
+	}
+
+	return page, perPage
+}
+
+// AdminPageHandler 显示管理后台页面，非管理员返回403
+func AdminPageHandler(c *gin.Context) {
+	user, found := data.GetRequestUser(c.Request)
+	if !found || !data.IsAdmin(user) {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"message": "需要使用管理员账户登录后才能访问管理后台",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin.html", gin.H{
+		"username": user.Username,
+	})
+}
+
+// AdminContentsHandler 分页列出所有来源的内容，支持按来源、类型、标签、公开状态和关键词筛选
+func AdminContentsHandler(c *gin.Context) {
+	page, perPage := parsePageParams(c)
+
+	filter := data.AdminContentFilter{
+		Source:     strings.TrimSpace(c.Query("source")),
+		Type:       c.Query("type"),
+		Tag:        c.Query("tag"),
+		Query:      strings.TrimSpace(c.Query("query")),
+		Visibility: c.Query("visibility"),
+	}
+	total, results := data.FindAllContentsPaginated(filter, page, perPage)
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":    total,
+		"page":     page,
+		"per_page": perPage,
+		"items":    results,
+	})
+}
+
+// AdminUnpublishContentHandler 强制将内容设为不公开
+func AdminUnpublishContentHandler(c *gin.Context) {
+	shortID := c.Param("id")
+	if err := data.UnpublishContent(shortID, c.GetString(adminActorKey), strings.TrimSpace(c.PostForm("reason"))); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("内容 %s 已设为不公开", shortID),
+	})
+}
+
+// AdminUnlockContentHandler 解除强制取消公开的锁定，允许作者重新公开内容
+func AdminUnlockContentHandler(c *gin.Context) {
+	shortID := c.Param("id")
+	if err := data.UnlockContent(shortID, c.GetString(adminActorKey), strings.TrimSpace(c.PostForm("reason"))); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("内容 %s 已解除锁定", shortID),
+	})
+}
+
+// AdminDeleteContentHandler 强制删除任意来源的内容
+func AdminDeleteContentHandler(c *gin.Context) {
+	shortID := c.Param("id")
+	if err := data.RemoveContent(shortID, c.GetString(adminActorKey), strings.TrimSpace(c.Query("reason"))); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("内容 %s 已删除", shortID),
+	})
+}
+
+// AdminCollectionsHandler 分页列出所有来源的合集，支持按来源、公开状态和标题筛选
+func AdminCollectionsHandler(c *gin.Context) {
+	page, perPage := parsePageParams(c)
+
+	total, results := data.FindAllCollectionsPaginated(
+		strings.TrimSpace(c.Query("source")),
+		strings.TrimSpace(c.Query("query")),
+		c.Query("visibility"),
+		page, perPage,
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":    total,
+		"page":     page,
+		"per_page": perPage,
+		"items":    results,
+	})
+}
+
+// AdminUnpublishCollectionHandler 强制将合集设为不公开
+func AdminUnpublishCollectionHandler(c *gin.Context) {
+	shortID := c.Param("id")
+	if err := data.UnpublishCollection(shortID, c.GetString(adminActorKey), strings.TrimSpace(c.PostForm("reason"))); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("合集 %s 已设为不公开", shortID),
+	})
+}
+
+// AdminUnlockCollectionHandler 解除合集的锁定，允许创建者重新公开合集
+func AdminUnlockCollectionHandler(c *gin.Context) {
+	shortID := c.Param("id")
+	if err := data.UnlockCollection(shortID, c.GetString(adminActorKey), strings.TrimSpace(c.PostForm("reason"))); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("合集 %s 已解除锁定", shortID),
+	})
+}
+
+// AdminDeleteCollectionHandler 强制删除任意来源的合集，合集中的内容不会被删除
+func AdminDeleteCollectionHandler(c *gin.Context) {
+	shortID := c.Param("id")
+	if err := data.RemoveCollection(shortID, c.GetString(adminActorKey), strings.TrimSpace(c.Query("reason"))); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("合集 %s 已删除", shortID),
+	})
+}
+
+// AdminBansHandler 列出被封禁的来源
+func AdminBansHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"items": data.FindBans(),
+	})
+}
+
+// AdminBanSourceHandler 禁止来源发布内容
+// unpublish: 为 "true" 时同时将该来源的所有公开内容和合集设为不公开
+func AdminBanSourceHandler(c *gin.Context) {
+	source := strings.TrimSpace(c.PostForm("source"))
+	if source == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "未提供来源标识"})
+		return
+	}
+
+	unpublished, err := data.BanSource(source, c.GetString(adminActorKey), strings.TrimSpace(c.PostForm("reason")), c.PostForm("unpublish") == "true")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"unpublished": unpublished,
+		"message":     fmt.Sprintf("已封禁来源 %s", source),
+	})
+}
+
+// AdminUnbanSourceHandler 解除来源的封禁
+func AdminUnbanSourceHandler(c *gin.Context) {
+	source := c.Param("source")
+	if err := data.UnbanSource(source, c.GetString(adminActorKey)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("已解除来源 %s 的封禁", source),
+	})
+}
+
+// AdminAuditLogHandler 分页返回管理员操作的审计日志
+func AdminAuditLogHandler(c *gin.Context) {
+	page, perPage := parsePageParams(c)
+	total, logs := data.FindAuditLogs(c.Query("action"), page, perPage)
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":    total,
+		"page":     page,
+		"per_page": perPage,
+		"items":    logs,
+	})
+}
+
+// AdminReportsHandler 分页列出被举报的内容，status 默认为 "open"
+func AdminReportsHandler(c *gin.Context) {
+	page, perPage := parsePageParams(c)
+	total, results := data.FindReportedContents(c.Query("status"), page, perPage)
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":     total,
+		"page":      page,
+		"per_page":  perPage,
+		"items":     results,
+		"threshold": data.ReportHideThreshold(),
+	})
+}
+
+// AdminContentReportsHandler 返回内容收到的所有举报
+func AdminContentReportsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"items": data.FindContentReports(c.Param("id")),
+	})
+}
+
+// AdminResolveReportsHandler 处理内容的待处理举报
+// decision: "dismiss" 驳回举报并恢复被自动隐藏的内容，"uphold" 确认举报并保持不公开
+// note:     处理说明（可选）
+func AdminResolveReportsHandler(c *gin.Context) {
+	shortID := c.Param("id")
+	decision := c.PostForm("decision")
+	if err := data.ResolveReports(shortID, c.GetString(adminActorKey), decision, c.PostForm("note")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message := fmt.Sprintf("已驳回内容 %s 的举报", shortID)
+	if decision == data.ReportDecisionUphold {
+		message = fmt.Sprintf("已确认内容 %s 的举报，内容已设为不公开", shortID)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+	})
+}
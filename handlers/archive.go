@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+	"sharesth/utils"
+)
+
+// ExportHandler 以zip归档的形式导出当前用户的全部内容
+func ExportHandler(c *gin.Context) {
+	clientIdentifier := data.GetClientIdentifier(c.Request)
+
+	filename := fmt.Sprintf("sharesth-export-%s.zip", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	// 归档直接写入响应，开始写入后无法再返回错误状态码，只能记录日志
+	if err := data.WriteExportArchive(c.Writer, clientIdentifier); err != nil {
+		log.Printf("导出内容失败: %v", err)
+	}
+}
+
+// ImportHandler 从zip归档导入内容到当前用户
+// file:         zip归档，可以是导出的归档，也可以是Markdown、文本、代码、图片等文件的集合
+// preserve_ids: 为 "true" 时尽量保留清单中的原短链接ID，被占用时使用新的ID并在结果中说明
+// is_public:    没有清单时导入内容的公开状态
+func ImportHandler(c *gin.Context) {
+	clientIdentifier := data.GetClientIdentifier(c.Request)
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "未提供导入的归档文件"})
+		return
+	}
+	if file.Size > utils.MaxImportArchiveSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("归档大小不能超过 %s", utils.FormatFileSize(utils.MaxImportArchiveSize))})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("打开上传文件失败: %v", err)})
+		return
+	}
+	defer src.Close()
+
+	results, err := data.ImportArchive(src, file.Size, clientIdentifier, data.ImportOptions{
+		PreserveIDs: c.PostForm("preserve_ids") == "true",
+		IsPublic:    c.PostForm("is_public") == "true",
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 统计各状态的数量，原ID被占用或未导入的条目作为冲突单独列出
+	counts := map[string]int{}
+	conflicts := make([]data.ImportResult, 0)
+	for _, result := range results {
+		counts[result.Status]++
+		if result.Status == data.ImportStatusRenamed || result.Status == data.ImportStatusSkipped {
+			conflicts = append(conflicts, result)
+		}
+	}
+	imported := counts[data.ImportStatusImported] + counts[data.ImportStatusRenamed]
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   counts[data.ImportStatusFailed] == 0,
+		"imported":  imported,
+		"skipped":   counts[data.ImportStatusSkipped],
+		"failed":    counts[data.ImportStatusFailed],
+		"conflicts": conflicts,
+		"results":   results,
+		"message":   fmt.Sprintf("导入%d个内容，跳过%d个，失败%d个", imported, counts[data.ImportStatusSkipped], counts[data.ImportStatusFailed]),
+	})
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+	"sharesth/models"
+)
+
+// setSessionCookie 写入会话Cookie，maxAge 小于0时删除Cookie
+func setSessionCookie(c *gin.Context, token string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(data.SessionCookieName, token, maxAge, "/", "", c.Request.TLS != nil, true)
+}
+
+// startSession 为账户创建会话并写入Cookie
+func startSession(c *gin.Context, user models.User) error {
+	token, _, err := data.CreateSession(user.ID)
+	if err != nil {
+		return err
+	}
+
+	setSessionCookie(c, token, int(data.SessionExpiration.Seconds()))
+	return nil
+}
+
+// LoginPageHandler 显示登录/注册页面，已登录时显示账户信息
+func LoginPageHandler(c *gin.Context) {
+	user, loggedIn := data.GetSessionUser(c.Request)
+	c.HTML(http.StatusOK, "login.html", gin.H{
+		"loggedIn": loggedIn,
+		"user":     user,
+	})
+}
+
+// RegisterHandler 处理账户注册请求
+func RegisterHandler(c *gin.Context) {
+	if _, loggedIn := data.GetSessionUser(c.Request); loggedIn {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "您已登录，请先退出登录"})
+		return
+	}
+
+	username := c.PostForm("username")
+	password := c.PostForm("password")
+
+	// 默认认领当前浏览器的匿名身份，保留已分享的内容
+	var userID string
+	if c.PostForm("claim_browser") != "false" {
+		userID = data.GetFingerprintIdentifier(c.Request)
+	} else {
+		userID = data.AllocateUserID()
+	}
+
+	user, err := data.CreateUser(username, password, userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, data.ErrInvalidUsername) || errors.Is(err, data.ErrInvalidPassword) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, data.ErrUsernameTaken) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("注册新账户: %s, 用户ID: %s", user.Username, user.UserID)
+
+	if err := startSession(c, user); err != nil {
+		log.Printf("创建会话失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "注册成功，但自动登录失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"message":  "注册成功",
+		"username": user.Username,
+		"user_id":  user.UserID,
+	})
+}
+
+// LoginHandler 处理账户登录请求
+func LoginHandler(c *gin.Context) {
+	user, err := data.AuthenticateUser(c.PostForm("username"), c.PostForm("password"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := startSession(c, user); err != nil {
+		log.Printf("创建会话失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "登录失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"message":  "登录成功",
+		"username": user.Username,
+		"user_id":  user.UserID,
+	})
+}
+
+// LogoutHandler 处理退出登录请求
+func LogoutHandler(c *gin.Context) {
+	if token, err := c.Cookie(data.SessionCookieName); err == nil {
+		if err := data.DeleteSession(token); err != nil {
+			log.Printf("删除会话失败: %v", err)
+		}
+	}
+
+	setSessionCookie(c, "", -1)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "已退出登录",
+	})
+}
+
+// CurrentUserHandler 返回当前登录的账户信息
+func CurrentUserHandler(c *gin.Context) {
+	user, loggedIn := data.GetSessionUser(c.Request)
+	if !loggedIn {
+		c.JSON(http.StatusOK, gin.H{
+			"logged_in": false,
+			"user_id":   data.GetClientIdentifier(c.Request),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"logged_in": true,
+		"username":  user.Username,
+		"user_id":   user.UserID,
+	})
+}
+
+// CreateClaimCodeHandler 为当前账户生成一次性认领码，用于关联其他浏览器
+func CreateClaimCodeHandler(c *gin.Context) {
+	user, loggedIn := data.GetSessionUser(c.Request)
+	if !loggedIn {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "请先登录"})
+		return
+	}
+
+	code, expiresAt, err := data.CreateClaimCode(user.ID)
+	if err != nil {
+		log.Printf("生成认领码失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成认领码失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"code":       code,
+		"expires_at": expiresAt,
+	})
+}
+
+// RedeemClaimCodeHandler 在另一个浏览器中兑换认领码，将该浏览器的匿名身份合并到账户并登录
+func RedeemClaimCodeHandler(c *gin.Context) {
+	if _, loggedIn := data.GetSessionUser(c.Request); loggedIn {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "当前浏览器已登录，请先退出登录"})
+		return
+	}
+
+	code := c.PostForm("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "未提供认领码"})
+		return
+	}
+
+	fromUserID := data.GetFingerprintIdentifier(c.Request)
+	user, moved, err := data.RedeemClaimCode(code, fromUserID, c.Request.UserAgent())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, data.ErrInvalidClaimCode) || errors.Is(err, data.ErrAlreadyMerged) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := startSession(c, user); err != nil {
+		log.Printf("创建会话失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "关联成功，但自动登录失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"message":     fmt.Sprintf("已关联到账户 %s，转移内容 %d 条", user.Username, moved),
+		"moved_count": moved,
+		"username":    user.Username,
+		"user_id":     user.UserID,
+	})
+}
+
+// IdentityMergesHandler 返回当前账户的身份合并记录
+func IdentityMergesHandler(c *gin.Context) {
+	user, loggedIn := data.GetSessionUser(c.Request)
+	if !loggedIn {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "请先登录"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": data.FindIdentityMerges(user.ID),
+	})
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+	"sharesth/models"
+)
+
+// 批量操作支持的动作
+const (
+	bulkActionDelete    = "delete"
+	bulkActionPublic    = "public"
+	bulkActionPrivate   = "private"
+	bulkActionAddTag    = "add_tag"
+	bulkActionSetExpiry = "set_expiry"
+)
+
+// BulkContentHandler 对多个内容执行同一操作，所有内容在同一个事务中处理，并逐项返回结果
+// content_ids: 逗号分隔的内容短链接ID
+// action:      delete、public、private、add_tag（需要 tag）或 set_expiry（需要 expires_in、expires_at 或 max_views）
+func BulkContentHandler(c *gin.Context) {
+	clientIdentifier := data.GetClientIdentifier(c.Request)
+
+	shortIDs := parseContentIDs(c.PostForm("content_ids"))
+	if len(shortIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "未提供内容ID"})
+		return
+	}
+
+	var results []data.BulkResult
+	var err error
+
+	// 被封禁的来源仍然可以删除自己的内容，但不能修改
+	action := c.PostForm("action")
+	if action != bulkActionDelete && data.IsSourceBanned(clientIdentifier) {
+		c.JSON(http.StatusForbidden, gin.H{"error": data.ErrSourceBanned.Error()})
+		return
+	}
+	switch action {
+	case bulkActionDelete:
+		// 路由只校验了写入权限，删除还需要删除权限
+		if !checkScope(c, data.ScopeDelete) {
+			return
+		}
+		results, err = data.BulkDeleteContents(clientIdentifier, shortIDs)
+
+	case bulkActionPublic, bulkActionPrivate:
+		isPublic := action == bulkActionPublic
+		results, err = data.BulkUpdateContents(clientIdentifier, shortIDs, func(content *models.Content) error {
+			content.IsPublic = isPublic
+			content.UpdateTime = time.Now()
+			return nil
+		})
+
+	case bulkActionAddTag:
+		name, tagErr := data.NormalizeTag(c.PostForm("tag"))
+		if tagErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tagErr.Error()})
+			return
+		}
+		tags, tagErr := data.ResolveTags([]string{name})
+		if tagErr != nil || len(tags) == 0 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "创建标签失败"})
+			return
+		}
+		results, err = data.BulkAddContentTag(clientIdentifier, shortIDs, tags[0])
+
+	case bulkActionSetExpiry:
+		opts, optsErr := parseExpirationOptions(c)
+		if optsErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": optsErr.Error()})
+			return
+		}
+		if !opts.hasExpiresAt && !opts.hasMaxViews {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "未提供有效期设置"})
+			return
+		}
+		results, err = data.BulkUpdateContents(clientIdentifier, shortIDs, func(content *models.Content) error {
+			opts.apply(content)
+			content.UpdateTime = time.Now()
+			return nil
+		})
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("不支持的批量操作: %s", action)})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   succeeded == len(results),
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+		"results":   results,
+		"message":   fmt.Sprintf("成功处理%d个内容，%d个失败", succeeded, len(results)-succeeded),
+	})
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/models"
+	"sharesth/utils"
+)
+
+// applyLanguageOption 根据请求设置代码内容的语言
+// language: 语言名称或别名，为空或 "auto" 时根据文件名和代码内容自动识别
+func applyLanguageOption(c *gin.Context, content *models.Content, filename string) error {
+	language := c.PostForm("language")
+	if language == "" || language == "auto" {
+		content.Language = utils.DetectLanguage(content.Data, filename)
+		return nil
+	}
+
+	normalized, ok := utils.NormalizeLanguage(language)
+	if !ok {
+		return fmt.Errorf("不支持的语言: %s", language)
+	}
+	content.Language = normalized
+
+	return nil
+}
+
+// renderCode 渲染带语法高亮和行号的代码页面
+func renderCode(c *gin.Context, content models.Content, isOwner bool) {
+	highlighted, err := utils.HighlightCode(content.Data, content.Language)
+	if err != nil {
+		log.Printf("代码高亮失败: %v", err)
+		c.String(http.StatusInternalServerError, "渲染代码失败")
+		return
+	}
+
+	c.HTML(http.StatusOK, "code.html", gin.H{
+		"title":        content.Title,
+		"content":      content.Data,
+		"highlighted":  highlighted,
+		"highlightCSS": utils.HighlightCSS(),
+		"language":     content.Language,
+		"createTime":   content.CreateTime,
+		"updateTime":   content.UpdateTime,
+		"isOwner":      isOwner,
+		"shortID":      content.ShortID,
+		"shortLink":    content.ShortID,
+		"expiresAt":    content.ExpiresAt,
+		"maxViews":     content.MaxViews,
+		"viewCount":    content.ViewCount,
+	})
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+	"sharesth/models"
+)
+
+// 合集标题的最大长度（字符数）
+const maxCollectionTitleLength = 100
+
+// parseContentIDs 解析逗号分隔的内容短链接ID列表，保持原有顺序
+func parseContentIDs(input string) []string {
+	ids := make([]string, 0)
+	for _, id := range strings.Split(input, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// collectionShortLink 返回合集的完整访问链接
+func collectionShortLink(c *gin.Context, collection models.Collection) string {
+	return fmt.Sprintf("http://%s/c/%s", c.Request.Host, collection.ShortID)
+}
+
+// applyCollectionFields 根据请求设置合集的标题、描述和公开状态，未提交的字段保持不变
+func applyCollectionFields(c *gin.Context, collection *models.Collection) error {
+	if title, ok := c.GetPostForm("title"); ok {
+		title = strings.TrimSpace(title)
+		if title == "" {
+			return fmt.Errorf("合集标题不能为空")
+		}
+		if utf8.RuneCountInString(title) > maxCollectionTitleLength {
+			return fmt.Errorf("合集标题过长，最多%d个字符", maxCollectionTitleLength)
+		}
+		collection.Title = title
+	}
+	if description, ok := c.GetPostForm("description"); ok {
+		collection.Description = strings.TrimSpace(description)
+	}
+	if isPublic, ok := c.GetPostForm("is_public"); ok {
+		collection.IsPublic = isPublic == "true"
+	}
+	return nil
+}
+
+// loadOwnedCollection 加载当前用户拥有的合集，失败时直接写入错误响应
+func loadOwnedCollection(c *gin.Context) (models.Collection, bool) {
+	clientIdentifier := data.GetClientIdentifier(c.Request)
+
+	collection, err := data.LoadCollectionBySource(c.Param("id"), clientIdentifier)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return models.Collection{}, false
+	}
+
+	return collection, true
+}
+
+// collectionDetail 返回合集的详细信息
+func collectionDetail(c *gin.Context, collection models.Collection, items []map[string]interface{}) gin.H {
+	return gin.H{
+		"short_id":    collection.ShortID,
+		"title":       collection.Title,
+		"description": collection.Description,
+		"is_public":   collection.IsPublic,
+		"locked":      collection.Locked,
+		"createTime":  collection.CreateTime,
+		"updateTime":  collection.UpdateTime,
+		"shortLink":   collectionShortLink(c, collection),
+		"items":       items,
+	}
+}
+
+// ListCollectionsHandler 返回当前用户的合集列表
+func ListCollectionsHandler(c *gin.Context) {
+	clientIdentifier := data.GetClientIdentifier(c.Request)
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": data.FindCollectionsBySource(clientIdentifier),
+	})
+}
+
+// CreateCollectionHandler 创建合集
+// content_ids: 逗号分隔的内容短链接ID，按显示顺序排列，只能包含自己的内容
+func CreateCollectionHandler(c *gin.Context) {
+	clientIdentifier := data.GetClientIdentifier(c.Request)
+
+	now := time.Now()
+	collection := models.Collection{
+		Source:     clientIdentifier,
+		CreateTime: now,
+		UpdateTime: now,
+	}
+	if _, ok := c.GetPostForm("title"); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "合集标题不能为空"})
+		return
+	}
+	if err := applyCollectionFields(c, &collection); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := data.CreateCollection(&collection, parseContentIDs(c.PostForm("content_ids"))); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"short_id":  collection.ShortID,
+		"shortLink": collectionShortLink(c, collection),
+		"message":   "合集已创建",
+	})
+}
+
+// CollectionDetailHandler 返回合集详情和其中的全部内容
+func CollectionDetailHandler(c *gin.Context) {
+	collection, ok := loadOwnedCollection(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, collectionDetail(c, collection, data.FindCollectionItems(collection, true)))
+}
+
+// UpdateCollectionHandler 更新合集的标题、描述和公开状态
+func UpdateCollectionHandler(c *gin.Context) {
+	collection, ok := loadOwnedCollection(c)
+	if !ok {
+		return
+	}
+
+	if err := applyCollectionFields(c, &collection); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := data.UpdateCollection(&collection); err != nil {
+		if errors.Is(err, data.ErrCollectionLocked) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "合集已更新",
+	})
+}
+
+// DeleteCollectionHandler 删除合集，合集中的内容不会被删除
+func DeleteCollectionHandler(c *gin.Context) {
+	collection, ok := loadOwnedCollection(c)
+	if !ok {
+		return
+	}
+
+	if err := data.DeleteCollection(collection); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "合集已删除",
+	})
+}
+
+// SetCollectionItemsHandler 按给定顺序替换合集中的内容，用于批量调整和排序
+func SetCollectionItemsHandler(c *gin.Context) {
+	collection, ok := loadOwnedCollection(c)
+	if !ok {
+		return
+	}
+
+	if err := data.SetCollectionItems(&collection, parseContentIDs(c.PostForm("content_ids"))); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collectionDetail(c, collection, data.FindCollectionItems(collection, true)))
+}
+
+// AddCollectionItemHandler 将内容追加到合集末尾
+func AddCollectionItemHandler(c *gin.Context) {
+	collection, ok := loadOwnedCollection(c)
+	if !ok {
+		return
+	}
+
+	contentID := strings.TrimSpace(c.PostForm("content_id"))
+	if contentID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "未提供内容ID"})
+		return
+	}
+
+	if err := data.AddCollectionItem(&collection, contentID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "内容已加入合集",
+	})
+}
+
+// RemoveCollectionItemHandler 从合集中移除内容，内容本身不会被删除
+func RemoveCollectionItemHandler(c *gin.Context) {
+	collection, ok := loadOwnedCollection(c)
+	if !ok {
+		return
+	}
+
+	if err := data.RemoveCollectionItem(&collection, c.Param("contentID")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "内容已从合集中移除",
+	})
+}
+
+// CollectionPageHandler 显示合集页面
+// 创建者可以看到合集中的全部内容；其他人只能访问公开的合集，并且只能看到其中公开的内容
+func CollectionPageHandler(c *gin.Context) {
+	collection, err := data.LoadCollection(c.Param("shortID"))
+	isOwner := err == nil && collection.Source == data.GetClientIdentifier(c.Request)
+	if err != nil || (!collection.IsPublic && !isOwner) {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"message": "未找到合集或链接已失效",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "collection.html", gin.H{
+		"collection": collection,
+		"items":      data.FindCollectionItems(collection, isOwner),
+		"isOwner":    isOwner,
+	})
+}
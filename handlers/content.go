@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -44,8 +46,11 @@ func MyContentAPIHandler(c *gin.Context) {
 	// 获取类型筛选参数
 	typeFilter := c.Query("type")
 
-	// 获取总记录数、分页数据和类型统计
-	total, results, typeCounts := data.FindContentsBySourcePaginated(clientIdentifier, query, typeFilter, page, perPage)
+	// 获取标签筛选参数
+	tagFilter := c.Query("tag")
+
+	// 获取总记录数、分页数据、类型统计和标签统计
+	total, results, typeCounts, tagCounts := data.FindContentsBySourcePaginated(clientIdentifier, query, typeFilter, tagFilter, page, perPage)
 
 	// 返回JSON结果
 	c.JSON(http.StatusOK, gin.H{
@@ -55,6 +60,7 @@ func MyContentAPIHandler(c *gin.Context) {
 		"per_page":   perPage,
 		"items":      results,
 		"typeCounts": typeCounts,
+		"tagCounts":  tagCounts,
 	})
 }
 
@@ -85,7 +91,11 @@ func ToggleContentVisibilityHandler(c *gin.Context) {
 	content.IsPublic = !content.IsPublic
 
 	// 保存更改
-	err = data.UpdateContent(&content)
+	err = data.UpdateContent(&content, clientIdentifier)
+	if errors.Is(err, data.ErrContentUnderReview) || errors.Is(err, data.ErrContentLocked) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新内容状态失败"})
 		return
@@ -171,7 +181,7 @@ func ContentDetailHandler(c *gin.Context) {
 		result["content"] = content.Data
 
 		// 添加内容摘要
-		if content.Type == "markdown" || content.Type == "text" {
+		if content.IsTextual() {
 			if len(content.Data) > 200 {
 				result["summary"] = content.Data[:200] + "..."
 			} else {
@@ -184,7 +194,7 @@ func ContentDetailHandler(c *gin.Context) {
 		}
 	} else {
 		// 只返回摘要
-		if content.Type == "markdown" || content.Type == "text" {
+		if content.IsTextual() {
 			if len(content.Data) > 200 {
 				result["summary"] = content.Data[:200] + "..."
 			} else {
@@ -197,6 +207,22 @@ func ContentDetailHandler(c *gin.Context) {
 		}
 	}
 
+	// 代码内容附带语言
+	if content.Type == "code" {
+		result["language"] = content.Language
+	}
+
+	// 内容标签
+	result["tags"] = content.TagNames()
+
+	// 文件内容附带文件信息
+	if content.Type == "file" {
+		result["file_name"] = content.FileName
+		result["file_size"] = content.FileSize
+		result["mime_type"] = content.MimeType
+		result["content_url"] = "/" + content.ShortID + "/download"
+	}
+
 	// 返回结果
 	c.JSON(http.StatusOK, result)
 }
@@ -241,6 +267,13 @@ func EditContentPageHandler(c *gin.Context) {
 		"content_raw": content.Data,
 		"title":       content.Title,
 		"is_public":   content.IsPublic,
+		"expires_at":  content.ExpiresAt,
+		"max_views":   content.MaxViews,
+		"view_count":  content.ViewCount,
+		"protected":   content.IsProtected(),
+		"language":    content.Language,
+		"file_name":   content.FileName,
+		"tags":        strings.Join(content.TagNames(), ", "),
 	})
 }
 
@@ -284,6 +317,13 @@ func EditContentByPathHandler(c *gin.Context) {
 		"content_raw": content.Data,
 		"title":       content.Title,
 		"is_public":   content.IsPublic,
+		"expires_at":  content.ExpiresAt,
+		"max_views":   content.MaxViews,
+		"view_count":  content.ViewCount,
+		"protected":   content.IsProtected(),
+		"language":    content.Language,
+		"file_name":   content.FileName,
+		"tags":        strings.Join(content.TagNames(), ", "),
 	})
 }
 
@@ -318,16 +358,45 @@ func UpdateContentHandler(c *gin.Context) {
 	// 获取公开设置
 	content.IsPublic = c.PostForm("is_public") == "true"
 
+	// 获取过期设置
+	expiration, err := parseExpirationOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	expiration.apply(&content)
+
+	// 设置或移除访问密码
+	if err := applyPasswordOption(c, &content); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 设置标签
+	tagsSubmitted, err := applyTagsOption(c, &content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// 记录内容类型和是否有新内容提交
 	log.Printf("内容类型: %s, 标题: %s, 公开状态: %v", content.Type, content.Title, content.IsPublic)
 
-	// 如果是文本或Markdown类型，更新内容数据
-	if content.Type == "text" || content.Type == "markdown" {
+	// 如果是文本、Markdown或代码类型，更新内容数据
+	if content.IsTextual() {
 		newContent := c.PostForm("content")
 		if newContent != "" {
 			content.Data = newContent
 			log.Printf("更新文本/Markdown内容, 长度: %d", len(newContent))
 		}
+
+		// 代码内容可以修改语言，"auto" 表示重新识别
+		if content.Type == "code" && c.PostForm("language") != "" {
+			if err := applyLanguageOption(c, &content, ""); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
 	} else if content.Type == "image" {
 		// 图片类型不需要更新内容本身，只更新标题和公开状态
 		// 但为了调试，我们记录一下是否收到了content参数
@@ -342,11 +411,22 @@ func UpdateContentHandler(c *gin.Context) {
 	content.UpdateTime = time.Now()
 
 	// 保存更新
-	if err := data.UpdateContent(&content); err != nil {
+	if err := data.UpdateContent(&content, clientIdentifier); err != nil {
+		if errors.Is(err, data.ErrContentUnderReview) || errors.Is(err, data.ErrContentLocked) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		log.Printf("保存内容更新失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新内容失败"})
 		return
 	}
+	if tagsSubmitted {
+		if err := data.SetContentTags(&content); err != nil {
+			log.Printf("保存内容标签失败: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "更新标签失败"})
+			return
+		}
+	}
 
 	log.Printf("内容更新成功: ID=%s, 类型=%s", content.ShortID, content.Type)
 
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/models"
+	"sharesth/utils"
+)
+
+// expirationOptions 请求中携带的过期设置，未提交的字段保持内容原值不变
+type expirationOptions struct {
+	expiresAt    *time.Time
+	hasExpiresAt bool
+	maxViews     int
+	hasMaxViews  bool
+}
+
+// parseExpirationOptions 解析过期设置
+// expires_in: 有效时长，如 "30m"、"2h"、"7d"，"never" 或 "0" 表示永不过期
+// expires_at: RFC3339 格式的过期时间，优先级低于 expires_in
+// max_views:  最大访问次数，0 表示不限制，1 表示阅后即焚
+func parseExpirationOptions(c *gin.Context) (expirationOptions, error) {
+	var opts expirationOptions
+
+	if expiresIn := c.PostForm("expires_in"); expiresIn != "" {
+		opts.hasExpiresAt = true
+		if expiresIn != "never" && expiresIn != "0" {
+			d, err := utils.ParseDuration(expiresIn)
+			if err != nil || d <= 0 {
+				return opts, fmt.Errorf("无效的有效期: %s", expiresIn)
+			}
+			expiresAt := time.Now().Add(d)
+			opts.expiresAt = &expiresAt
+		}
+	} else if expiresAtParam := c.PostForm("expires_at"); expiresAtParam != "" {
+		opts.hasExpiresAt = true
+		if expiresAtParam != "never" {
+			expiresAt, err := time.Parse(time.RFC3339, expiresAtParam)
+			if err != nil {
+				return opts, fmt.Errorf("无效的过期时间: %s", expiresAtParam)
+			}
+			if !expiresAt.After(time.Now()) {
+				return opts, fmt.Errorf("过期时间必须晚于当前时间")
+			}
+			opts.expiresAt = &expiresAt
+		}
+	}
+
+	if maxViewsParam := c.PostForm("max_views"); maxViewsParam != "" {
+		maxViews, err := strconv.Atoi(maxViewsParam)
+		if err != nil || maxViews < 0 {
+			return opts, fmt.Errorf("无效的最大访问次数: %s", maxViewsParam)
+		}
+		opts.maxViews = maxViews
+		opts.hasMaxViews = true
+	}
+
+	return opts, nil
+}
+
+// apply 将过期设置应用到内容上
+func (o expirationOptions) apply(content *models.Content) {
+	if o.hasExpiresAt {
+		content.ExpiresAt = o.expiresAt
+	}
+	if o.hasMaxViews {
+		content.MaxViews = o.maxViews
+		// 重新设置访问次数限制时从零开始计数
+		content.ViewCount = 0
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+)
+
+// DownloadFileHandler 以附件形式下载文件类型的内容，每次下载计入一次访问
+func DownloadFileHandler(c *gin.Context) {
+	shortID := c.Param("shortID")
+
+	content, err := data.LoadContent(shortID)
+	if err != nil {
+		if errors.Is(err, data.ErrContentExpired) {
+			renderExpired(c)
+			return
+		}
+		c.String(http.StatusNotFound, "未找到内容或链接已失效")
+		return
+	}
+
+	if content.Type != "file" {
+		c.String(http.StatusNotFound, "该内容不是文件")
+		return
+	}
+
+	// 设置了访问密码的内容需要先解锁
+	isOwner := content.Source == data.GetClientIdentifier(c.Request)
+	if !isOwner && !data.IsContentUnlocked(c.Request, content) {
+		renderUnlock(c, http.StatusUnauthorized, content, "")
+		return
+	}
+
+	// 创建者本人的下载和HEAD请求不计入访问次数
+	if !isOwner && c.Request.Method == http.MethodGet {
+		if err := data.RecordContentView(&content); err != nil {
+			if errors.Is(err, data.ErrContentExpired) {
+				renderExpired(c)
+				return
+			}
+			log.Printf("记录访问失败: %v", err)
+		}
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": content.FileName}))
+	if content.MimeType != "" {
+		c.Header("Content-Type", content.MimeType)
+	}
+	serveObject(c, data.UploadKey(content.Data), time.Time{})
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+)
+
+// HealthHandler 健康检查，报告数据库、缓存和限流使用的后端及其状态
+// 数据库不可用时返回503，缓存不可用时服务仍可工作，返回200
+func HealthHandler(c *gin.Context) {
+	report := data.CheckHealth(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status == "error" {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+)
+
+// RequireScope 校验请求携带的API令牌是否拥有指定授权范围
+// 未携带 Authorization 头的请求（浏览器会话或匿名访问）直接放行
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checkScope(c, scope) {
+			c.Next()
+		}
+	}
+}
+
+// checkScope 校验请求携带的API令牌是否拥有指定授权范围，不满足时中止请求并返回 false
+// 供路由授权范围之外还需要其他授权范围的处理函数使用，未携带 Authorization 头的请求直接通过
+func checkScope(c *gin.Context, scope string) bool {
+	if _, found := data.GetBearerToken(c.Request); !found {
+		return true
+	}
+
+	apiToken, found := data.GetRequestAPIToken(c.Request)
+	if !found {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API令牌无效或已吊销"})
+		return false
+	}
+
+	if !apiToken.HasScope(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API令牌缺少授权范围: " + scope})
+		return false
+	}
+
+	user, found := data.FindUserByID(apiToken.AccountID)
+	if !found {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API令牌对应的账户不存在"})
+		return false
+	}
+
+	// 保存已认证的账户，后续 GetClientIdentifier 无需再次查询
+	c.Request = data.WithRequestUser(c.Request, user)
+	return true
+}
+
+// adminActorKey 上下文中保存当前管理员名称的键，用于审计日志
+const adminActorKey = "adminActor"
+
+// RequireAdmin 要求请求来自已登录的管理员账户（浏览器会话或带 admin 授权范围的API令牌），或携带配置的管理员令牌
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token, found := data.GetBearerToken(c.Request); found {
+			if name, ok := data.FindAdminToken(token); ok {
+				c.Set(adminActorKey, name)
+				c.Next()
+				return
+			}
+
+			// 管理员账户的普通令牌（如只读令牌）不能执行管理操作
+			apiToken, ok := data.GetRequestAPIToken(c.Request)
+			if !ok {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API令牌无效或已吊销"})
+				return
+			}
+			if !apiToken.HasScope(data.ScopeAdmin) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API令牌缺少授权范围: " + data.ScopeAdmin})
+				return
+			}
+		}
+
+		user, found := data.GetRequestUser(c.Request)
+		if !found {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "请先登录"})
+			return
+		}
+
+		if !data.IsAdmin(user) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "需要管理员权限"})
+			return
+		}
+
+		c.Set(adminActorKey, user.Username)
+		c.Request = data.WithRequestUser(c.Request, user)
+		c.Next()
+	}
+}
+
+// RejectBannedSource 拒绝已被管理员封禁的来源发布或修改内容
+func RejectBannedSource() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if data.IsSourceBanned(data.GetClientIdentifier(c.Request)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": data.ErrSourceBanned.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimit 按客户端标识和IP地址分别限流，任一令牌桶用尽时返回429，Limit 为0时不限流
+// 响应头中的 RateLimit-* 取两个令牌桶中剩余较少的一个
+func RateLimit(limit data.RateLimit) gin.HandlerFunc {
+	return rateLimitBy(limit, func(c *gin.Context) []string {
+		return []string{"client:" + data.GetClientIdentifier(c.Request), "ip:" + c.ClientIP()}
+	})
+}
+
+// RateLimitPerParam 按路由参数和IP地址的组合限流，如限制每个IP对同一短链接尝试访问密码的次数
+// 客户端标识可以通过更换浏览器特征伪造，因此只按IP计数
+func RateLimitPerParam(limit data.RateLimit, param string) gin.HandlerFunc {
+	return rateLimitBy(limit, func(c *gin.Context) []string {
+		return []string{"ip:" + c.ClientIP() + ":" + c.Param(param)}
+	})
+}
+
+// rateLimitBy 对 keys 返回的每个令牌桶分别限流，任一令牌桶用尽时返回429，Limit 为0时不限流
+// 浏览器提交的表单返回纯文本错误，其他请求返回JSON
+func rateLimitBy(limit data.RateLimit, keys func(c *gin.Context) []string) gin.HandlerFunc {
+	if limit.Limit == 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	policy := fmt.Sprintf("%d;w=%d", limit.Limit, int(limit.Period.Seconds()))
+
+	return func(c *gin.Context) {
+		var result data.RateLimitResult
+		for i, key := range keys(c) {
+			keyResult := data.TakeRateLimitToken(key, limit)
+			if i == 0 || !keyResult.Allowed || keyResult.Remaining < result.Remaining {
+				result = keyResult
+			}
+			if !keyResult.Allowed {
+				break
+			}
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			message := fmt.Sprintf("请求过于频繁，请在%d秒后重试", retryAfter)
+			if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+				c.Abort()
+				c.String(http.StatusTooManyRequests, message)
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": message})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ceilSeconds 将时长向上取整为秒
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+	"sharesth/models"
+	"sharesth/storage"
+	"sharesth/utils"
+)
+
+// applyPasswordOption 根据请求设置或移除内容的访问密码
+// password: 新的访问密码；remove_password 为 "true" 时移除密码
+func applyPasswordOption(c *gin.Context, content *models.Content) error {
+	if c.PostForm("remove_password") == "true" {
+		content.Password = ""
+		return nil
+	}
+
+	password := c.PostForm("password")
+	if password == "" {
+		return nil
+	}
+
+	hash, err := data.HashContentPassword(password)
+	if err != nil {
+		return err
+	}
+	content.Password = hash
+
+	return nil
+}
+
+// renderUnlock 渲染输入访问密码的页面
+func renderUnlock(c *gin.Context, status int, content models.Content, message string) {
+	c.HTML(status, "unlock.html", gin.H{
+		"shortID": content.ShortID,
+		"message": message,
+	})
+}
+
+// UnlockContentHandler 校验访问密码，成功后写入签名的解锁Cookie并跳转回内容页面
+func UnlockContentHandler(c *gin.Context) {
+	shortID := c.Param("shortID")
+
+	content, err := data.LoadContent(shortID)
+	if err != nil {
+		if errors.Is(err, data.ErrContentExpired) {
+			renderExpired(c)
+			return
+		}
+		c.String(http.StatusNotFound, "未找到内容或链接已失效")
+		return
+	}
+
+	if !data.CheckContentPassword(content, c.PostForm("password")) {
+		log.Printf("内容 %s 访问密码错误", shortID)
+		renderUnlock(c, http.StatusUnauthorized, content, "密码错误，请重试")
+		return
+	}
+
+	token, _ := data.CreateUnlockToken(content)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(data.UnlockCookiePrefix+content.ShortID, token, int(data.UnlockExpiration.Seconds()), "/", "", c.Request.TLS != nil, true)
+
+	c.Redirect(http.StatusSeeOther, "/"+content.ShortID)
+}
+
+// UploadsHandler 提供上传文件的访问，只有引用文件的内容仍然有效且可以访问时才允许
+func UploadsHandler(c *gin.Context) {
+	// 清理路径，防止访问上传目录之外的文件
+	filePath := path.Join(utils.UploadsDir, path.Clean("/"+c.Param("filepath")))
+	key := data.UploadKey(filePath)
+
+	if _, err := data.Store.Stat(c.Request.Context(), key); err != nil {
+		if !errors.Is(err, storage.ErrNotExist) {
+			log.Printf("获取上传文件信息失败: %v", err)
+		}
+		c.String(http.StatusNotFound, "文件不存在")
+		return
+	}
+
+	if !canAccessUpload(c, filePath, data.FindContentsByUploadPath(filePath)) {
+		c.String(http.StatusForbidden, "无权访问该文件，请通过分享链接查看")
+		return
+	}
+
+	// 只有图片允许在页面中直接显示，其他文件（包括可以携带脚本的SVG）一律作为附件下载，
+	// 避免上传的HTML等文件在本站域名下执行
+	if mimeType := mime.TypeByExtension(path.Ext(filePath)); !strings.HasPrefix(mimeType, "image/") || strings.HasPrefix(mimeType, "image/svg") {
+		c.Header("Content-Disposition", "attachment")
+	}
+
+	// 存储后端支持时直接重定向到签名URL，减少应用转发的流量
+	if data.RedirectUploads {
+		signedURL, err := data.Store.SignedURL(c.Request.Context(), key, data.SignedURLExpiration)
+		if err == nil {
+			c.Header("Cache-Control", "private, no-store")
+			c.Redirect(http.StatusFound, signedURL)
+			return
+		}
+		if !errors.Is(err, storage.ErrSignedURLNotSupported) {
+			log.Printf("生成签名URL失败: %v", err)
+		}
+	}
+
+	serveObject(c, key, time.Time{})
+}
+
+// serveObject 从存储后端读取对象并写入响应，调用方已设置 Content-Type 或 ETag 时不覆盖
+// modTime 不为零时代替对象的修改时间用于 Last-Modified 和条件请求
+func serveObject(c *gin.Context, key string, modTime time.Time) {
+	reader, info, err := data.Store.Get(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotExist) {
+			c.String(http.StatusNotFound, "文件不存在")
+			return
+		}
+		log.Printf("读取上传文件失败: %v", err)
+		c.String(http.StatusInternalServerError, "读取文件失败")
+		return
+	}
+	defer reader.Close()
+
+	if c.Writer.Header().Get("Content-Type") == "" && info.ContentType != "" {
+		c.Header("Content-Type", info.ContentType)
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	if c.Writer.Header().Get("ETag") == "" && info.ETag != "" {
+		c.Header("ETag", info.ETag)
+	}
+	if modTime.IsZero() {
+		modTime = info.ModTime
+	}
+
+	// 可随机读取的对象（如本地文件）支持范围请求和条件请求
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, path.Base(key), modTime, seeker)
+		return
+	}
+
+	if !modTime.IsZero() {
+		c.Header("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, reader, nil)
+}
+
+// canAccessUpload 判断请求能否访问被指定内容引用的上传文件
+// 已过期或访问次数已用尽的内容不再授权访问；访问次数受限的内容只能通过计入访问后签发的令牌访问，
+// 否则直接访问文件会绕过访问计数
+func canAccessUpload(c *gin.Context, filePath string, contents []models.Content) bool {
+	// 没有内容引用的文件（如编辑器中刚上传的图片）不做限制
+	if len(contents) == 0 {
+		return true
+	}
+
+	// 令牌在最后一次访问计入后签发，此时内容可能已经用尽访问次数
+	if data.VerifyUploadGrant(filePath, c.Query(data.UploadGrantParam)) {
+		return true
+	}
+
+	clientIdentifier := data.GetClientIdentifier(c.Request)
+	for _, content := range contents {
+		if content.IsExpired() {
+			continue
+		}
+		// 内容创建者始终可以访问
+		if content.Source == clientIdentifier {
+			return true
+		}
+		if content.MaxViews == 0 && data.IsContentUnlocked(c.Request, content) {
+			return true
+		}
+	}
+
+	return false
+}
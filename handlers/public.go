@@ -37,8 +37,11 @@ func PublicContentAPIHandler(c *gin.Context) {
 	// 获取类型筛选参数
 	typeFilter := c.Query("type")
 
-	// 获取总记录数、分页数据和类型统计
-	total, results, typeCounts := data.FindPublicContentsPaginated(query, typeFilter, page, perPage)
+	// 获取标签筛选参数
+	tagFilter := c.Query("tag")
+
+	// 获取总记录数、分页数据、类型统计和标签统计
+	total, results, typeCounts, tagCounts := data.FindPublicContentsPaginated(query, typeFilter, tagFilter, page, perPage)
 
 	// 返回JSON结果
 	c.JSON(http.StatusOK, gin.H{
@@ -47,5 +50,6 @@ func PublicContentAPIHandler(c *gin.Context) {
 		"per_page":   perPage,
 		"items":      results,
 		"typeCounts": typeCounts,
+		"tagCounts":  tagCounts,
 	})
 }
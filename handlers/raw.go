@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+	"sharesth/models"
+)
+
+// 内容协商支持的表示格式
+const (
+	formatHTML     = "text/html"
+	formatText     = "text/plain"
+	formatMarkdown = "text/markdown"
+	formatJSON     = "application/json"
+)
+
+// contentMimeType 返回图片或文件内容的MIME类型，旧数据没有记录时根据扩展名推断
+func contentMimeType(content models.Content) string {
+	if content.MimeType != "" {
+		return content.MimeType
+	}
+	if mimeType := mime.TypeByExtension(path.Ext(content.Data)); mimeType != "" {
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
+// contentFormats 返回内容可以提供的表示格式，请求未指定 Accept 或接受任意格式时使用第一个
+func contentFormats(content models.Content) []string {
+	var formats []string
+	if content.IsTextual() {
+		formats = append(formats, formatText)
+		if content.Type == "markdown" {
+			formats = append(formats, formatMarkdown)
+		}
+	} else if content.Type == "image" || content.Type == "file" {
+		// 上传的HTML或JSON文件不能占用页面和JSON表示的格式
+		mimeType := contentMimeType(content)
+		if base, _, _ := strings.Cut(mimeType, ";"); base != formatHTML && base != formatJSON {
+			formats = append(formats, mimeType)
+		}
+	}
+	return append(formats, formatJSON, formatHTML)
+}
+
+// contentETag 根据内容的修改时间和表示格式生成ETag，JSON表示中包含访问次数，因此一并计入
+func contentETag(content models.Content, format string) string {
+	if format == formatJSON {
+		return fmt.Sprintf(`"%s-%x-json-%d"`, content.ShortID, content.UpdateTime.UnixNano(), content.ViewCount)
+	}
+	base, _, _ := strings.Cut(format, ";")
+	return fmt.Sprintf(`"%s-%x-%s"`, content.ShortID, content.UpdateTime.UnixNano(), strings.ReplaceAll(base, "/", "."))
+}
+
+// setContentValidators 设置内容的ETag和Last-Modified响应头
+func setContentValidators(c *gin.Context, content models.Content, format string) {
+	c.Header("ETag", contentETag(content, format))
+	c.Header("Last-Modified", content.UpdateTime.UTC().Format(http.TimeFormat))
+}
+
+// isNotModified 根据条件请求头判断客户端缓存是否仍然有效，If-None-Match 优先于 If-Modified-Since
+func isNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+
+	return false
+}
+
+// contentRepresentation 返回内容的JSON表示，非创建者看不到内容的来源标识
+// 图片和文件的上传路径也不对非创建者公开，文件只能通过计入访问的原始数据和下载地址获取
+func contentRepresentation(content models.Content, isOwner bool) models.Content {
+	if !isOwner {
+		content.Source = ""
+		if content.Type == "image" || content.Type == "file" {
+			content.Data = ""
+		}
+	}
+	return content
+}
+
+// serveContentAs 以指定格式返回内容的原始数据或JSON表示，支持条件请求
+func serveContentAs(c *gin.Context, content models.Content, format string) {
+	if format == "" {
+		c.String(http.StatusNotAcceptable, "不支持请求的内容格式，可选: %s", strings.Join(contentFormats(content), ", "))
+		return
+	}
+
+	// 设置了访问密码的内容需要先解锁
+	isOwner := content.Source == data.GetClientIdentifier(c.Request)
+	if !isOwner && !data.IsContentUnlocked(c.Request, content) {
+		c.String(http.StatusUnauthorized, "该内容受密码保护，请先通过分享链接解锁")
+		return
+	}
+
+	// 内容可能受访问次数限制或密码保护，不允许共享缓存，客户端每次都需要重新验证
+	c.Header("Cache-Control", "private, no-cache")
+	setContentValidators(c, content, format)
+	if isNotModified(c.Request, contentETag(content, format), content.UpdateTime) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	// 创建者本人的访问不计入访问次数
+	if !isOwner {
+		if err := data.RecordContentView(&content); err != nil {
+			if errors.Is(err, data.ErrContentExpired) {
+				c.String(http.StatusGone, "该内容已过期或访问次数已用尽")
+				return
+			}
+			log.Printf("记录访问失败: %v", err)
+		}
+		// 访问次数变化后刷新JSON表示的ETag
+		setContentValidators(c, content, format)
+	}
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	switch {
+	case format == formatJSON:
+		c.JSON(http.StatusOK, contentRepresentation(content, isOwner))
+	case content.IsTextual():
+		c.Data(http.StatusOK, format+"; charset=utf-8", []byte(content.Data))
+	default:
+		// 与上传文件的访问一致，只有图片直接显示，其他文件和SVG作为附件下载
+		if content.Type == "file" {
+			c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": content.FileName}))
+		} else if strings.HasPrefix(format, "image/svg") {
+			c.Header("Content-Disposition", "attachment")
+		}
+		c.Header("Content-Type", format)
+		serveObject(c, data.UploadKey(content.Data), content.UpdateTime)
+	}
+}
+
+// RawContentHandler 返回内容的原始数据：文本、Markdown和代码以纯文本返回，图片和文件返回文件本身
+func RawContentHandler(c *gin.Context) {
+	shortID := c.Param("shortID")
+
+	content, err := data.LoadContent(shortID)
+	if err != nil {
+		if errors.Is(err, data.ErrContentExpired) {
+			c.String(http.StatusGone, "该内容已过期或访问次数已用尽")
+			return
+		}
+		c.String(http.StatusNotFound, "未找到内容或链接已失效")
+		return
+	}
+
+	switch {
+	case content.IsTextual():
+		serveContentAs(c, content, formatText)
+	case content.Type == "image" || content.Type == "file":
+		serveContentAs(c, content, contentMimeType(content))
+	default:
+		c.String(http.StatusNotFound, "该内容没有原始数据")
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+)
+
+// ReportContentHandler 举报内容
+// category: 举报类型，见 data.ReportCategories
+// detail:   举报说明（可选）
+func ReportContentHandler(c *gin.Context) {
+	clientIdentifier := data.GetClientIdentifier(c.Request)
+
+	hidden, err := data.CreateReport(c.Param("id"), c.PostForm("category"), c.PostForm("detail"), clientIdentifier, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"hidden":  hidden,
+		"message": "举报已提交，感谢您的反馈",
+	})
+}
+
+// ReportCategoriesHandler 返回支持的举报类型
+func ReportCategoriesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"categories": data.ReportCategories,
+	})
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+	"sharesth/models"
+	"sharesth/utils"
+)
+
+// loadOwnedContent 加载当前用户拥有的内容，失败时直接写入错误响应
+func loadOwnedContent(c *gin.Context) (models.Content, string, bool) {
+	clientIdentifier := data.GetClientIdentifier(c.Request)
+
+	content, err := data.LoadContentBySource(c.Param("id"), clientIdentifier)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "找不到内容或您无权访问"})
+		return models.Content{}, clientIdentifier, false
+	}
+
+	return content, clientIdentifier, true
+}
+
+// parseRevisionParam 解析版本号参数
+func parseRevisionParam(value string) (int, error) {
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision <= 0 {
+		return 0, fmt.Errorf("无效的版本号: %s", value)
+	}
+	return revision, nil
+}
+
+// ContentRevisionsHandler 返回内容的版本列表
+func ContentRevisionsHandler(c *gin.Context) {
+	content, _, ok := loadOwnedContent(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"short_id": content.ShortID,
+		"items":    data.FindContentRevisions(content.ID),
+	})
+}
+
+// ContentRevisionHandler 返回内容指定版本的完整数据
+func ContentRevisionHandler(c *gin.Context) {
+	content, _, ok := loadOwnedContent(c)
+	if !ok {
+		return
+	}
+
+	revisionNumber, err := parseRevisionParam(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revision, err := data.LoadContentRevision(content.ID, revisionNumber)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "版本不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// ContentDiffHandler 返回两个版本之间的统一格式行级diff，仅支持文本和Markdown
+// 参数 from 和 to 为版本号，省略 to 时为最新版本，省略 from 时为 to 的上一个版本
+func ContentDiffHandler(c *gin.Context) {
+	content, _, ok := loadOwnedContent(c)
+	if !ok {
+		return
+	}
+
+	if !content.IsTextual() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "只有文本、Markdown和代码内容支持比较差异"})
+		return
+	}
+
+	// 确定要比较的版本
+	revisions := data.FindContentRevisions(content.ID)
+	if len(revisions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "该内容没有历史版本"})
+		return
+	}
+
+	to := revisions[0]["revision"].(int)
+	if toParam := c.Query("to"); toParam != "" {
+		var err error
+		if to, err = parseRevisionParam(toParam); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// 未指定起始版本时与上一个版本比较
+	from := to - 1
+	if fromParam := c.Query("from"); fromParam != "" {
+		var err error
+		if from, err = parseRevisionParam(fromParam); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else if from < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("版本%d是第一个版本，请指定要比较的版本", to)})
+		return
+	}
+	if from == to {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不能与同一个版本比较差异"})
+		return
+	}
+
+	fromRevision, err := data.LoadContentRevision(content.ID, from)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("版本%d不存在", from)})
+		return
+	}
+	toRevision, err := data.LoadContentRevision(content.ID, to)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("版本%d不存在", to)})
+		return
+	}
+
+	if utils.LineCount(fromRevision.Data) > utils.MaxDiffLines || utils.LineCount(toRevision.Data) > utils.MaxDiffLines {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("内容超过%d行，无法比较差异", utils.MaxDiffLines)})
+		return
+	}
+
+	diff := utils.UnifiedDiff(fromRevision.Data, toRevision.Data,
+		fmt.Sprintf("%s@%d", content.ShortID, from), fmt.Sprintf("%s@%d", content.ShortID, to),
+		utils.DiffContextLines)
+
+	c.JSON(http.StatusOK, gin.H{
+		"short_id": content.ShortID,
+		"from":     from,
+		"to":       to,
+		"diff":     diff,
+	})
+}
+
+// RestoreRevisionHandler 将内容恢复到指定版本，恢复后产生一个新版本
+func RestoreRevisionHandler(c *gin.Context) {
+	content, clientIdentifier, ok := loadOwnedContent(c)
+	if !ok {
+		return
+	}
+
+	revisionNumber, err := parseRevisionParam(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := data.RestoreContentRevision(&content, revisionNumber, clientIdentifier); err != nil {
+		if errors.Is(err, data.ErrContentUnderReview) || errors.Is(err, data.ErrContentLocked) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("恢复版本失败: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("已恢复到版本%d", revisionNumber),
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -96,6 +97,56 @@ func handleImageContent(c *gin.Context, clientIdentifier, title string, isPublic
 	return content, nil
 }
 
+// handleFileContent 处理任意类型的文件内容
+func handleFileContent(c *gin.Context, clientIdentifier, title string, isPublic bool) (models.Content, error) {
+	// 获取上传的文件
+	file, err := c.FormFile("file")
+	if err != nil {
+		return models.Content{}, fmt.Errorf("无法获取上传的文件: %v", err)
+	}
+
+	if file.Size > utils.MaxUploadFileSize {
+		return models.Content{}, fmt.Errorf("文件大小不能超过 %s", utils.FormatFileSize(utils.MaxUploadFileSize))
+	}
+
+	// 打开上传的文件
+	src, err := file.Open()
+	if err != nil {
+		return models.Content{}, fmt.Errorf("打开上传文件失败: %v", err)
+	}
+	defer src.Close()
+
+	// 使用SaveUploadedFile保存文件并实现去重
+	uploaded, err := data.SaveUploadedFile(src, file.Filename)
+	if err != nil {
+		return models.Content{}, fmt.Errorf("保存文件失败: %v", err)
+	}
+
+	// 使用文件名作为默认标题（如果没有提供）
+	fileName := filepath.Base(file.Filename)
+	if title == "" {
+		title = "文件: " + fileName
+	}
+
+	// 设置当前时间
+	currentTime := time.Now()
+
+	content := models.Content{
+		Type:       "file",
+		Data:       uploaded.Path, // 保存文件路径
+		Source:     clientIdentifier,
+		CreateTime: currentTime,
+		UpdateTime: currentTime,
+		Title:      title,
+		IsPublic:   isPublic,
+		FileName:   fileName,
+		MimeType:   uploaded.MimeType,
+		FileSize:   uploaded.Size,
+	}
+
+	return content, nil
+}
+
 // ShareHandler 处理内容分享
 func ShareHandler(c *gin.Context) {
 	// 获取客户端标识
@@ -112,7 +163,7 @@ func ShareHandler(c *gin.Context) {
 	log.Printf("接收到分享请求，内容类型: %s, 客户端ID: %s", contentType, clientIdentifier)
 
 	// 验证内容类型是否有效
-	if contentType != "markdown" && contentType != "text" && contentType != "image" {
+	if contentType != "markdown" && contentType != "text" && contentType != "code" && contentType != "image" && contentType != "file" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的内容类型"})
 		return
 	}
@@ -125,17 +176,29 @@ func ShareHandler(c *gin.Context) {
 	isPublic := c.PostForm("is_public") == "true"
 	log.Printf("内容公开设置: %v", isPublic)
 
-	var (
-		content models.Content
-		err     error
-	)
+	// 获取过期设置
+	expiration, err := parseExpirationOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var content models.Content
 
 	// 根据内容类型处理不同的上传
 	switch contentType {
 	case "markdown", "text":
 		content, err = handleTextContent(c, contentType, clientIdentifier, title, isPublic)
+	case "code":
+		content, err = handleTextContent(c, contentType, clientIdentifier, title, isPublic)
+		if err == nil {
+			// 标题可作为文件名帮助识别语言，如 "main.go"
+			err = applyLanguageOption(c, &content, title)
+		}
 	case "image":
 		content, err = handleImageContent(c, clientIdentifier, title, isPublic)
+	case "file":
+		content, err = handleFileContent(c, clientIdentifier, title, isPublic)
 	}
 
 	if err != nil {
@@ -143,8 +206,23 @@ func ShareHandler(c *gin.Context) {
 		return
 	}
 
+	// 应用过期设置
+	expiration.apply(&content)
+
+	// 设置访问密码
+	if err := applyPasswordOption(c, &content); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 设置标签
+	if _, err := applyTagsOption(c, &content); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// 生成短链接ID并保存内容
-	shortID := utils.GenerateShortID(8)
+	shortID := data.NewShortID()
 
 	// 保存内容到数据库
 	if err := data.SaveContent(shortID, content); err != nil {
@@ -1,13 +1,22 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"sharesth/data"
+	"sharesth/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// renderExpired 渲染内容已失效的410页面
+func renderExpired(c *gin.Context) {
+	c.HTML(http.StatusGone, "expired.html", gin.H{
+		"message": "该内容已过期或访问次数已用尽，无法再查看。",
+	})
+}
+
 // ShortLinkHandler 处理短链接访问
 func ShortLinkHandler(c *gin.Context) {
 	// 获取短链接ID
@@ -16,17 +25,45 @@ func ShortLinkHandler(c *gin.Context) {
 	// 加载内容
 	content, err := data.LoadContent(shortID)
 	if err != nil {
+		if errors.Is(err, data.ErrContentExpired) {
+			renderExpired(c)
+			return
+		}
 		log.Printf("加载内容失败: %v", err)
 		c.String(http.StatusNotFound, "未找到内容或链接已失效")
 		return
 	}
 
+	// 非浏览器请求（如 curl 或 API 客户端）按 Accept 头返回原始数据或JSON表示
+	c.Header("Vary", "Accept")
+	if format := c.NegotiateFormat(contentFormats(content)...); format != formatHTML {
+		serveContentAs(c, content, format)
+		return
+	}
+
 	// 获取当前访问者的客户端标识
 	clientIdentifier := data.GetClientIdentifier(c.Request)
 
 	// 判断当前用户是否是内容创建者
 	isOwner := content.Source == clientIdentifier
 
+	// 设置了访问密码的内容需要先解锁
+	if !isOwner && !data.IsContentUnlocked(c.Request, content) {
+		renderUnlock(c, http.StatusUnauthorized, content, "")
+		return
+	}
+
+	// 创建者本人的访问不计入访问次数，文件内容在下载时才计入
+	if !isOwner && content.Type != "file" {
+		if err := data.RecordContentView(&content); err != nil {
+			if errors.Is(err, data.ErrContentExpired) {
+				renderExpired(c)
+				return
+			}
+			log.Printf("记录访问失败: %v", err)
+		}
+	}
+
 	// 根据内容类型处理
 	switch content.Type {
 	case "markdown":
@@ -39,6 +76,9 @@ func ShortLinkHandler(c *gin.Context) {
 			"isOwner":    isOwner,
 			"shortID":    content.ShortID,
 			"shortLink":  content.ShortID,
+			"expiresAt":  content.ExpiresAt,
+			"maxViews":   content.MaxViews,
+			"viewCount":  content.ViewCount,
 		})
 	case "text":
 		c.HTML(http.StatusOK, "text.html", gin.H{
@@ -49,17 +89,47 @@ func ShortLinkHandler(c *gin.Context) {
 			"isOwner":    isOwner,
 			"shortID":    content.ShortID,
 			"shortLink":  content.ShortID,
+			"expiresAt":  content.ExpiresAt,
+			"maxViews":   content.MaxViews,
+			"viewCount":  content.ViewCount,
 		})
 	case "image":
+		// 访问次数受限的图片不能直接访问上传文件，使用本次访问签发的临时地址
+		imagePath := content.Data
+		if !isOwner && content.MaxViews > 0 {
+			imagePath = data.GrantedUploadURL(content.Data)
+		}
 		// 渲染图片内容页面
 		c.HTML(http.StatusOK, "image.html", gin.H{
 			"title":      content.Title,
-			"imagePath":  content.Data,
+			"imagePath":  imagePath,
+			"createTime": content.CreateTime,
+			"updateTime": content.UpdateTime,
+			"isOwner":    isOwner,
+			"shortID":    content.ShortID,
+			"shortLink":  content.ShortID,
+			"expiresAt":  content.ExpiresAt,
+			"maxViews":   content.MaxViews,
+			"viewCount":  content.ViewCount,
+		})
+	case "code":
+		// 渲染带语法高亮的代码页面
+		renderCode(c, content, isOwner)
+	case "file":
+		// 渲染文件下载页面
+		c.HTML(http.StatusOK, "file.html", gin.H{
+			"title":      content.Title,
+			"fileName":   content.FileName,
+			"fileSize":   utils.FormatFileSize(content.FileSize),
+			"mimeType":   content.MimeType,
 			"createTime": content.CreateTime,
 			"updateTime": content.UpdateTime,
 			"isOwner":    isOwner,
 			"shortID":    content.ShortID,
 			"shortLink":  content.ShortID,
+			"expiresAt":  content.ExpiresAt,
+			"maxViews":   content.MaxViews,
+			"viewCount":  content.ViewCount,
 		})
 	default:
 		c.String(http.StatusBadRequest, "不支持的内容类型")
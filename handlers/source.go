@@ -44,8 +44,11 @@ func SourceContentHandler(c *gin.Context) {
 	// 获取类型筛选参数
 	typeFilter := c.Query("type")
 
-	// 获取总记录数、分页数据和类型统计
-	total, results, typeCounts := data.FindContentsBySourcePaginated(source, query, typeFilter, page, perPage)
+	// 获取标签筛选参数
+	tagFilter := c.Query("tag")
+
+	// 获取总记录数、分页数据、类型统计和标签统计
+	total, results, typeCounts, tagCounts := data.FindContentsBySourcePaginated(source, query, typeFilter, tagFilter, page, perPage)
 
 	// 返回JSON结果
 	c.JSON(http.StatusOK, gin.H{
@@ -55,6 +58,7 @@ func SourceContentHandler(c *gin.Context) {
 		"per_page":   perPage,
 		"items":      results,
 		"typeCounts": typeCounts,
+		"tagCounts":  tagCounts,
 	})
 }
 
@@ -88,8 +92,11 @@ func SourceSearchAPIHandler(c *gin.Context) {
 	// 获取类型筛选参数
 	typeFilter := c.Query("type")
 
-	// 获取总记录数、分页数据和类型统计
-	total, results, typeCounts := data.FindContentsBySourcePaginated(source, query, typeFilter, page, perPage)
+	// 获取标签筛选参数
+	tagFilter := c.Query("tag")
+
+	// 获取总记录数、分页数据、类型统计和标签统计
+	total, results, typeCounts, tagCounts := data.FindContentsBySourcePaginated(source, query, typeFilter, tagFilter, page, perPage)
 
 	// 返回JSON结果
 	c.JSON(http.StatusOK, gin.H{
@@ -99,5 +106,6 @@ func SourceSearchAPIHandler(c *gin.Context) {
 		"per_page":   perPage,
 		"items":      results,
 		"typeCounts": typeCounts,
+		"tagCounts":  tagCounts,
 	})
 }
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+	"sharesth/models"
+)
+
+// applyTagsOption 根据请求设置内容的标签
+// tags: 逗号或空格分隔的标签列表，为空表示清除标签；未提交该字段时保持原有标签不变，返回 false
+func applyTagsOption(c *gin.Context, content *models.Content) (bool, error) {
+	input, ok := c.GetPostForm("tags")
+	if !ok {
+		return false, nil
+	}
+
+	names, err := data.ParseTags(input)
+	if err != nil {
+		return true, err
+	}
+
+	tags, err := data.ResolveTags(names)
+	if err != nil {
+		return true, err
+	}
+	content.Tags = tags
+
+	return true, nil
+}
+
+// TagPageHandler 显示带有指定标签的公开内容页面
+func TagPageHandler(c *gin.Context) {
+	tag, err := data.NormalizeTag(c.Param("tag"))
+	if err != nil {
+		c.String(http.StatusNotFound, "无效的标签")
+		return
+	}
+
+	c.HTML(http.StatusOK, "public.html", gin.H{
+		"tag": tag,
+	})
+}
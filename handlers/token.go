@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"sharesth/data"
+)
+
+// ListAPITokensHandler 返回当前账户的API令牌列表
+func ListAPITokensHandler(c *gin.Context) {
+	user, loggedIn := data.GetSessionUser(c.Request)
+	if !loggedIn {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "请先登录"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": data.FindAPITokens(user.ID),
+	})
+}
+
+// CreateAPITokenHandler 为当前账户创建API令牌
+func CreateAPITokenHandler(c *gin.Context) {
+	user, loggedIn := data.GetSessionUser(c.Request)
+	if !loggedIn {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "请先登录"})
+		return
+	}
+
+	// 授权范围支持逗号分隔或多个同名参数
+	var scopes []string
+	for _, value := range c.PostFormArray("scopes") {
+		scopes = append(scopes, strings.Split(value, ",")...)
+	}
+
+	apiToken, token, err := data.CreateAPIToken(user.ID, c.PostForm("name"), scopes)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, data.ErrInvalidScope) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("账户 %s 创建API令牌: %s (%s)", user.Username, apiToken.Name, apiToken.Scopes)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "令牌已创建，请立即保存，关闭后将无法再次查看",
+		"token":   token,
+		"item":    apiToken,
+	})
+}
+
+// RevokeAPITokenHandler 吊销当前账户的API令牌
+func RevokeAPITokenHandler(c *gin.Context) {
+	user, loggedIn := data.GetSessionUser(c.Request)
+	if !loggedIn {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "请先登录"})
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的令牌ID"})
+		return
+	}
+
+	if err := data.RevokeAPIToken(user.ID, uint(tokenID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "令牌已吊销",
+	})
+}
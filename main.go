@@ -7,72 +7,220 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"sharesth/cli"
+	"sharesth/config"
 	"sharesth/data"
 	"sharesth/handlers"
-	"sharesth/utils"
 )
 
 func main() {
+	// 子命令
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1], os.Args[2:]))
+	}
+
+	// 加载配置
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+	data.InitContentSettings(cfg.Content)
+	data.InitAdmins(cfg.Security)
+
 	// 初始化数据库
-	if err := data.InitDB(); err != nil {
+	if err := data.InitDB(cfg.Database); err != nil {
 		log.Fatalf("数据库初始化失败: %v", err)
 	}
 	defer data.CloseDB()
 
-	// 初始化Redis客户端
-	data.InitRedisClient()
+	// 管理员权限绑定到已注册的账户
+	if err := data.LoadAdminAccounts(); err != nil {
+		log.Fatalf("管理员初始化失败: %v", err)
+	}
+
+	// 初始化签名密钥
+	if err := data.InitSecretKey(cfg.Security); err != nil {
+		log.Fatalf("签名密钥初始化失败: %v", err)
+	}
+
+	// 初始化上传文件存储
+	if err := data.InitStorage(cfg.Storage); err != nil {
+		log.Fatalf("存储初始化失败: %v", err)
+	}
+
+	// 初始化用户ID缓存，使用Redis时限流计数也保存在Redis中
+	if err := data.InitCache(cfg.Cache, cfg.Redis); err != nil {
+		log.Fatalf("缓存初始化失败: %v", err)
+	}
 	defer data.CloseRedisClient()
 
 	// 加载已分配的用户ID到内存
 	data.LoadAllocatedUserIDs()
 
+	// 清理过期会话和认领码
+	data.DeleteExpiredSessions()
+	data.DeleteExpiredClaimCodes()
+
+	// 启动过期内容清理任务
+	data.StartContentReaper(data.ContentReapInterval)
+
+	// 启动无引用上传文件的回收任务
+	data.StartUploadGC(data.UploadGCInterval)
+
 	// 创建Gin路由
 	r := gin.Default()
 
+	// 只信任配置的反向代理转发的客户端IP，未配置时 X-Forwarded-For 不会影响 c.ClientIP()
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("可信代理配置无效: %v", err)
+	}
+
 	// 设置静态文件目录
 	r.Static("/static", "./static")
-	r.Static("/uploads", "./uploads")
+
+	// 健康检查
+	r.GET("/healthz", handlers.HealthHandler)
+
+	// 上传文件通过处理函数访问，以便校验受密码保护的内容
+	r.GET("/uploads/*filepath", handlers.UploadsHandler)
+	r.HEAD("/uploads/*filepath", handlers.UploadsHandler)
 
 	// 加载HTML模板
 	r.LoadHTMLGlob("templates/*.html")
 
+	// 访问密码的尝试次数按短链接ID和IP地址计数，防止暴力破解
+	unlockLimit := handlers.RateLimitPerParam(data.RateLimit{Name: "unlock", Limit: cfg.RateLimit.Unlock.Limit, Period: cfg.RateLimit.Unlock.Period.Duration}, "shortID")
+
 	// 前端页面路由
 	r.GET("/", handlers.IndexHandler)                          // 首页
 	r.GET("/my-content", handlers.MyContentPageHandler)        // 我的内容页面
 	r.GET("/public", handlers.PublicContentPageHandler)        // 公开内容页面
 	r.GET("/search", handlers.SourceSearchPageHandler)         // 搜索页面
+	r.GET("/login", handlers.LoginPageHandler)                 // 登录/注册页面
+	r.GET("/tags/:tag", handlers.TagPageHandler)               // 标签下的公开内容
+	r.GET("/c/:shortID", handlers.CollectionPageHandler)       // 合集页面
+	r.GET("/admin", handlers.AdminPageHandler)                 // 管理后台
 	r.GET("/edit/:shortID", handlers.EditContentByPathHandler) // 编辑页面
 	r.GET("/:shortID", handlers.ShortLinkHandler)
+	r.POST("/:shortID/unlock", unlockLimit, handlers.UnlockContentHandler) // 输入访问密码解锁
+	r.GET("/:shortID/download", handlers.DownloadFileHandler)              // 下载文件内容
+	r.GET("/:shortID/raw", handlers.RawContentHandler)                     // 文本内容的原始数据
+	r.HEAD("/:shortID/download", handlers.DownloadFileHandler)
 
 	// API路由 - 按资源分组
 	api := r.Group("/api")
 	{
-		// 内容相关API
+		// 账户相关API
+		auth := api.Group("/auth")
+		{
+			auth.POST("/register", handlers.RegisterHandler)           // 注册账户
+			auth.POST("/login", handlers.LoginHandler)                 // 登录
+			auth.POST("/logout", handlers.LogoutHandler)               // 退出登录
+			auth.GET("/me", handlers.CurrentUserHandler)               // 当前账户信息
+			auth.POST("/claim-codes", handlers.CreateClaimCodeHandler) // 生成认领码
+			auth.POST("/claim", handlers.RedeemClaimCodeHandler)       // 兑换认领码，关联当前浏览器
+			auth.GET("/merges", handlers.IdentityMergesHandler)        // 身份合并记录
+		}
+
+		// API令牌管理（仅限浏览器会话）
+		tokens := api.Group("/tokens")
+		{
+			tokens.GET("", handlers.ListAPITokensHandler)         // 获取令牌列表
+			tokens.POST("", handlers.CreateAPITokenHandler)       // 创建令牌
+			tokens.DELETE("/:id", handlers.RevokeAPITokenHandler) // 吊销令牌
+		}
+
+		// 内容相关API，携带API令牌时按授权范围校验
+		read := handlers.RequireScope(data.ScopeRead)
+		write := handlers.RequireScope(data.ScopeWrite)
+		remove := handlers.RequireScope(data.ScopeDelete)
+		// 被封禁的来源不能发布或修改内容
+		notBanned := handlers.RejectBannedSource()
+		// 创建内容和上传文件的限流，每个客户端标识和IP地址分别计数
+		createLimit := rateLimit("create", cfg.RateLimit.Create)
+		uploadLimit := rateLimit("upload", cfg.RateLimit.Upload)
+		importLimit := rateLimit("import", cfg.RateLimit.Import)
+		reportLimit := rateLimit("report", cfg.RateLimit.Report)
+
 		contents := api.Group("/contents")
 		{
-			contents.GET("", handlers.MyContentAPIHandler)                         // 获取我的内容列表
-			contents.GET("/detail", handlers.ContentDetailHandler)                 // 获取内容详情
-			contents.POST("", handlers.ShareHandler)                               // 创建新内容
-			contents.POST("/update", handlers.UpdateContentHandler)                // 更新内容
-			contents.DELETE("", handlers.DeleteContentHandler)                     // 删除内容
-			contents.PATCH("/visibility", handlers.ToggleContentVisibilityHandler) // 切换可见性
-			contents.GET("/public", handlers.PublicContentAPIHandler)              // 获取公开内容
-			contents.GET("/search", handlers.SourceContentHandler)                 // 搜索内容
+			contents.GET("", read, handlers.MyContentAPIHandler)                                     // 获取我的内容列表
+			contents.GET("/detail", read, handlers.ContentDetailHandler)                             // 获取内容详情
+			contents.POST("", write, createLimit, notBanned, handlers.ShareHandler)                  // 创建新内容
+			contents.POST("/update", write, notBanned, handlers.UpdateContentHandler)                // 更新内容
+			contents.DELETE("", remove, handlers.DeleteContentHandler)                               // 删除内容
+			contents.PATCH("/visibility", write, notBanned, handlers.ToggleContentVisibilityHandler) // 切换可见性
+			contents.GET("/public", read, handlers.PublicContentAPIHandler)                          // 获取公开内容
+			contents.GET("/search", read, handlers.SourceContentHandler)                             // 搜索内容
+			contents.POST("/bulk", write, handlers.BulkContentHandler)                               // 批量操作内容
+
+			// 历史版本
+			contents.GET("/:id/revisions", read, handlers.ContentRevisionsHandler)                          // 版本列表
+			contents.GET("/:id/revisions/:rev", read, handlers.ContentRevisionHandler)                      // 指定版本
+			contents.POST("/:id/revisions/:rev/restore", write, notBanned, handlers.RestoreRevisionHandler) // 恢复到指定版本
+			contents.GET("/:id/diff", read, handlers.ContentDiffHandler)                                    // 版本差异
+
+			// 举报
+			contents.GET("/report-categories", handlers.ReportCategoriesHandler)            // 举报类型
+			contents.POST("/:id/report", write, reportLimit, handlers.ReportContentHandler) // 举报内容
+		}
+
+		// 合集相关API
+		collections := api.Group("/collections")
+		{
+			collections.GET("", read, handlers.ListCollectionsHandler)                               // 获取我的合集列表
+			collections.POST("", write, notBanned, handlers.CreateCollectionHandler)                 // 创建合集
+			collections.GET("/:id", read, handlers.CollectionDetailHandler)                          // 获取合集详情
+			collections.PUT("/:id", write, notBanned, handlers.UpdateCollectionHandler)              // 更新合集信息
+			collections.DELETE("/:id", remove, handlers.DeleteCollectionHandler)                     // 删除合集
+			collections.PUT("/:id/items", write, notBanned, handlers.SetCollectionItemsHandler)      // 替换或重新排序合集内容
+			collections.POST("/:id/items", write, notBanned, handlers.AddCollectionItemHandler)      // 添加内容到合集
+			collections.DELETE("/:id/items/:contentID", write, handlers.RemoveCollectionItemHandler) // 从合集移除内容
 		}
 
+		// 导出和导入内容
+		api.GET("/export", read, handlers.ExportHandler)                           // 导出我的全部内容为zip归档
+		api.POST("/import", write, importLimit, notBanned, handlers.ImportHandler) // 从zip归档导入内容
+
 		// 上传相关API
-		api.POST("/upload/image", handlers.UploadImageForMD) // Markdown编辑器的图片上传
-	}
+		api.POST("/upload/image", write, uploadLimit, notBanned, handlers.UploadImageForMD) // Markdown编辑器的图片上传
 
-	// 确保上传目录存在
-	os.MkdirAll(utils.UploadsDir, 0755)
+		// 管理员API
+		admin := api.Group("/admin", handlers.RequireAdmin())
+		{
+			admin.GET("/uploads/gc", handlers.UploadGCReportHandler) // 报告无引用的上传文件
+			admin.POST("/uploads/gc", handlers.UploadGCHandler)      // 回收无引用的上传文件
 
-	// 启动服务器
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080" // 默认端口
+			// 内容审核
+			admin.GET("/contents", handlers.AdminContentsHandler)                              // 列出所有来源的内容
+			admin.POST("/contents/:id/unpublish", handlers.AdminUnpublishContentHandler)       // 强制取消公开
+			admin.POST("/contents/:id/unlock", handlers.AdminUnlockContentHandler)             // 解除取消公开的锁定
+			admin.DELETE("/contents/:id", handlers.AdminDeleteContentHandler)                  // 强制删除内容
+			admin.GET("/collections", handlers.AdminCollectionsHandler)                        // 列出所有来源的合集
+			admin.POST("/collections/:id/unpublish", handlers.AdminUnpublishCollectionHandler) // 强制取消公开合集
+			admin.POST("/collections/:id/unlock", handlers.AdminUnlockCollectionHandler)       // 解除合集的锁定
+			admin.DELETE("/collections/:id", handlers.AdminDeleteCollectionHandler)            // 强制删除合集
+			admin.GET("/bans", handlers.AdminBansHandler)                                      // 被封禁的来源
+			admin.POST("/bans", handlers.AdminBanSourceHandler)                                // 封禁来源
+			admin.DELETE("/bans/:source", handlers.AdminUnbanSourceHandler)                    // 解除封禁
+			admin.GET("/audit", handlers.AdminAuditLogHandler)                                 // 审计日志
+
+			// 举报审核
+			admin.GET("/reports", handlers.AdminReportsHandler)                     // 被举报的内容
+			admin.GET("/reports/:id", handlers.AdminContentReportsHandler)          // 内容的举报记录
+			admin.POST("/reports/:id/resolve", handlers.AdminResolveReportsHandler) // 处理举报
+		}
 	}
 
-	log.Printf("服务器启动在: http://localhost:%s", port)
-	r.Run(fmt.Sprintf(":%s", port))
+	// 启动服务器
+	log.Printf("服务器启动在: http://localhost:%d", cfg.Server.Port)
+	r.Run(fmt.Sprintf(":%d", cfg.Server.Port))
+}
+
+// rateLimit 根据配置的限流规则创建限流中间件
+func rateLimit(name string, rule config.RateLimitRule) gin.HandlerFunc {
+	return handlers.RateLimit(data.RateLimit{Name: name, Limit: rule.Limit, Period: rule.Period.Duration})
 }
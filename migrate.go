@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"gorm.io/gorm/logger"
+
+	"sharesth/config"
+	"sharesth/data"
+)
+
+// migrateUsage migrate 子命令的用法
+const migrateUsage = `用法: sharesth migrate [配置参数] <命令>
+
+命令:
+  up        执行所有未执行的迁移
+  down [N]  撤销最近执行的 N 个迁移，默认为1
+  status    列出所有迁移及其执行状态
+
+配置参数与启动服务时相同，如 -config、-db、-db-dsn`
+
+// runMigrate 执行 migrate 子命令，返回进程退出码
+func runMigrate(args []string) int {
+	cfg, rest, err := config.LoadArgs(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 2
+	}
+	if len(rest) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	command := rest[0]
+	steps := 1
+	switch {
+	case command == "down" && len(rest) == 2:
+		steps, err = strconv.Atoi(rest[1])
+		if err != nil || steps <= 0 {
+			fmt.Fprintf(os.Stderr, "迁移数量必须是正整数: %s\n", rest[1])
+			return 2
+		}
+	case len(rest) > 1, command != "up" && command != "down" && command != "status":
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if err := data.OpenDB(cfg.Database, logger.Warn); err != nil {
+		fmt.Fprintf(os.Stderr, "数据库初始化失败: %v\n", err)
+		return 1
+	}
+	defer data.CloseDB()
+
+	switch command {
+	case "up":
+		ran, err := data.MigrateUp()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(ran) == 0 {
+			fmt.Println("数据库结构已是最新版本")
+		}
+		for _, migration := range ran {
+			fmt.Printf("已执行 %04d_%s\n", migration.Version, migration.Name)
+		}
+	case "down":
+		reverted, err := data.MigrateDown(steps)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(reverted) == 0 {
+			fmt.Println("没有可以撤销的迁移")
+		}
+		for _, migration := range reverted {
+			fmt.Printf("已撤销 %04d_%s\n", migration.Version, migration.Name)
+		}
+	case "status":
+		statuses, err := data.FindMigrationStatus()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		printMigrationStatus(statuses)
+	}
+
+	return 0
+}
+
+// printMigrationStatus 以表格形式输出迁移状态
+func printMigrationStatus(statuses []data.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "版本\t名称\t状态")
+	for _, status := range statuses {
+		state := "未执行"
+		if status.AppliedAt != nil {
+			state = "已执行于 " + status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		if status.Unknown {
+			state += "（程序中不存在）"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, state)
+	}
+	w.Flush()
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIToken 存储账户的个人API令牌，用于脚本等非浏览器客户端
+type APIToken struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	AccountID  uint      `json:"account_id" gorm:"index"`               // 所属账户
+	Name       string    `json:"name" gorm:"type:varchar(64)"`          // 令牌名称，便于识别用途
+	TokenHash  string    `json:"-" gorm:"type:varchar(64);uniqueIndex"` // 令牌的SHA-256哈希
+	Prefix     string    `json:"prefix" gorm:"type:varchar(16)"`        // 令牌前缀，用于在列表中辨认
+	Scopes     string    `json:"scopes" gorm:"type:varchar(64)"`        // 授权范围，逗号分隔，如 "read,write"
+	CreatedAt  time.Time `json:"created_at"`                            // 创建时间
+	LastUsedAt time.Time `json:"last_used_at"`                          // 最近使用时间
+}
+
+// TableName 指定表名
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// HasScope 判断令牌是否拥有指定的授权范围
+func (t APIToken) HasScope(scope string) bool {
+	for _, s := range strings.Split(t.Scopes, ",") {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
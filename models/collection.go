@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// Collection 内容合集，将同一来源的多个内容按顺序组织在一起，拥有独立的短链接
+type Collection struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ShortID     string    `json:"short_id" gorm:"type:varchar(15);uniqueIndex"`
+	Title       string    `json:"title" gorm:"type:varchar(255)"`
+	Description string    `json:"description" gorm:"type:text"`
+	Source      string    `json:"source" gorm:"type:varchar(32);index"` // 合集创建者的客户端标识
+	IsPublic    bool      `json:"is_public" gorm:"default:false"`       // 是否公开，不公开的合集只有创建者可以查看
+	Locked      bool      `json:"locked" gorm:"default:false"`          // 被管理员取消公开，解除锁定前创建者不能重新公开
+	CreateTime  time.Time `json:"create_time"`
+	UpdateTime  time.Time `json:"update_time"`
+}
+
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// CollectionItem 合集中的内容，Position 决定内容在合集中的顺序
+type CollectionItem struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CollectionID uint      `json:"collection_id" gorm:"uniqueIndex:idx_collection_item"`
+	ContentID    uint      `json:"content_id" gorm:"uniqueIndex:idx_collection_item;index"`
+	Position     int       `json:"position"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (CollectionItem) TableName() string {
+	return "collection_items"
+}
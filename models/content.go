@@ -6,15 +6,59 @@ import (
 
 // Content 存储内容的结构体
 type Content struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	ShortID    string    `json:"short_id" gorm:"type:varchar(15);uniqueIndex"`
-	Type       string    `json:"type" gorm:"type:varchar(10)"`         // "text", "markdown" 或 "image"
-	Data       string    `json:"data" gorm:"type:text"`                // 文本内容或图片路径
-	Source     string    `json:"source" gorm:"type:varchar(10);index"` // 数据来源（客户端标识）
-	CreateTime time.Time `json:"create_time" gorm:"index"`             // 创建时间
-	UpdateTime time.Time `json:"update_time" gorm:"index"`             // 最后修改时间
-	Title      string    `json:"title" gorm:"type:varchar(255)"`       // 内容标题
-	IsPublic   bool      `json:"is_public" gorm:"default:false"`       // 是否公开，默认为不公开
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	ShortID     string     `json:"short_id" gorm:"type:varchar(15);uniqueIndex"`
+	Type        string     `json:"type" gorm:"type:varchar(10)"`            // "text", "markdown", "code", "image" 或 "file"
+	Data        string     `json:"data"`                                    // 文本内容或上传文件路径，不指定列类型，MySQL中为longtext
+	Source      string     `json:"source" gorm:"type:varchar(32);index"`    // 数据来源（客户端标识）
+	CreateTime  time.Time  `json:"create_time" gorm:"index"`                // 创建时间
+	UpdateTime  time.Time  `json:"update_time" gorm:"index"`                // 最后修改时间
+	Title       string     `json:"title" gorm:"type:varchar(255)"`          // 内容标题
+	IsPublic    bool       `json:"is_public" gorm:"default:false"`          // 是否公开，默认为不公开
+	ExpiresAt   *time.Time `json:"expires_at" gorm:"index"`                 // 过期时间，为空表示永不过期
+	MaxViews    int        `json:"max_views" gorm:"default:0"`              // 最大访问次数，0表示不限制
+	ViewCount   int        `json:"view_count" gorm:"default:0"`             // 已访问次数
+	Password    string     `json:"-" gorm:"type:varchar(255)"`              // 访问密码的bcrypt哈希，为空表示无需密码
+	FileName    string     `json:"file_name" gorm:"type:varchar(255)"`      // 文件类型内容的原始文件名
+	MimeType    string     `json:"mime_type" gorm:"type:varchar(100)"`      // 文件类型内容根据文件内容识别的MIME类型
+	FileSize    int64      `json:"file_size" gorm:"default:0"`              // 文件类型内容的大小（字节）
+	Language    string     `json:"language" gorm:"type:varchar(32)"`        // 代码类型内容的编程语言，如 "go"
+	Tags        []Tag      `json:"tags" gorm:"many2many:content_tags;"`     // 内容标签
+	UnderReview bool       `json:"under_review" gorm:"default:false;index"` // 因举报过多被自动隐藏，等待管理员审核
+	Locked      bool       `json:"locked" gorm:"default:false"`             // 被管理员取消公开，解除锁定前作者不能重新公开
+}
+
+// TagNames 返回内容的标签名列表
+func (c Content) TagNames() []string {
+	names := make([]string, 0, len(c.Tags))
+	for _, tag := range c.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// IsTextual 判断内容数据是否为可直接显示的文本（纯文本、Markdown和代码）
+func (c Content) IsTextual() bool {
+	return c.Type == "text" || c.Type == "markdown" || c.Type == "code"
+}
+
+// IsProtected 判断内容是否设置了访问密码
+func (c Content) IsProtected() bool {
+	return c.Password != ""
+}
+
+// HidesPreview 判断列表和搜索结果中是否隐藏内容预览
+// 设置了访问密码或访问次数限制的内容只能通过短链接查看，预览会绕过密码和访问计数
+func (c Content) HidesPreview() bool {
+	return c.IsProtected() || c.MaxViews > 0
+}
+
+// IsExpired 判断内容是否已过期或访问次数已用尽
+func (c Content) IsExpired() bool {
+	if c.ExpiresAt != nil && !c.ExpiresAt.After(time.Now()) {
+		return true
+	}
+	return c.MaxViews > 0 && c.ViewCount >= c.MaxViews
 }
 
 // 数据目录路径
@@ -30,10 +74,11 @@ func (Content) TableName() string {
 
 // FileMD5 存储文件MD5与路径的映射关系
 type FileMD5 struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	MD5Hash   string    `json:"md5_hash" gorm:"type:varchar(32);uniqueIndex"`
-	FilePath  string    `json:"file_path" gorm:"type:varchar(255)"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	MD5Hash    string     `json:"md5_hash" gorm:"type:varchar(32);uniqueIndex"`
+	FilePath   string     `json:"file_path" gorm:"type:varchar(255)"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UploadedAt *time.Time `json:"uploaded_at" gorm:"index"` // 最近一次上传该文件的时间，重复上传时刷新，回收保护期从此时开始计算
 }
 
 func (FileMD5) TableName() string {
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// ContentRevision 存储内容的历史版本，每次创建、修改或恢复都会生成一个新版本
+type ContentRevision struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ContentID uint      `json:"content_id" gorm:"uniqueIndex:idx_content_revision"` // 对应 Content.ID
+	Revision  int       `json:"revision" gorm:"uniqueIndex:idx_content_revision"`   // 版本号，从1开始递增
+	Title     string    `json:"title" gorm:"type:varchar(255)"`                     // 该版本的标题
+	Data      string    `json:"data"`                                               // 该版本的内容数据，列类型与 Content.Data 相同
+	IsPublic  bool      `json:"is_public"`                                          // 该版本的公开状态
+	Editor    string    `json:"editor" gorm:"type:varchar(32)"`                     // 产生该版本的用户标识
+	Note      string    `json:"note" gorm:"type:varchar(64)"`                       // 版本说明，如"恢复自版本3"
+	CreatedAt time.Time `json:"created_at"`                                         // 版本创建时间
+}
+
+// TableName 指定表名
+func (ContentRevision) TableName() string {
+	return "content_revisions"
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+)
+
+// Ban 被禁止发布内容的来源
+type Ban struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Source    string    `json:"source" gorm:"type:varchar(32);uniqueIndex"` // 被封禁的客户端标识
+	Reason    string    `json:"reason" gorm:"type:varchar(255)"`            // 封禁原因
+	CreatedBy string    `json:"created_by" gorm:"type:varchar(64)"`         // 执行封禁的管理员
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Ban) TableName() string {
+	return "bans"
+}
+
+// AuditLog 管理员操作的审计日志
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Actor      string    `json:"actor" gorm:"type:varchar(64);index"`     // 执行操作的管理员
+	Action     string    `json:"action" gorm:"type:varchar(32);index"`    // 操作类型，如 "unpublish"、"delete"、"ban"
+	TargetType string    `json:"target_type" gorm:"type:varchar(16)"`     // 操作对象类型，"content" 或 "source"
+	TargetID   string    `json:"target_id" gorm:"type:varchar(64);index"` // 操作对象的短链接ID或来源标识
+	Detail     string    `json:"detail" gorm:"type:text"`                 // 操作原因等附加信息
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// Report 访问者对内容的举报
+type Report struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ContentID  uint       `json:"content_id" gorm:"index"`                   // 被举报内容的ID
+	ShortID    string     `json:"short_id" gorm:"type:varchar(15);index"`    // 被举报内容的短链接ID
+	Category   string     `json:"category" gorm:"type:varchar(16)"`          // 举报类型，如 "spam"、"credentials"
+	Detail     string     `json:"detail" gorm:"type:text"`                   // 举报说明
+	Reporter   string     `json:"reporter" gorm:"type:varchar(32);index"`    // 举报者的客户端标识
+	ReporterIP string     `json:"reporter_ip" gorm:"type:varchar(45);index"` // 举报者的IP地址，客户端标识可以伪造，重复举报和隐藏阈值按IP判断
+	Status     string     `json:"status" gorm:"type:varchar(16);index"`      // "open"、"dismissed" 或 "upheld"
+	ResolvedBy string     `json:"resolved_by" gorm:"type:varchar(64)"`       // 处理举报的管理员
+	ResolvedAt *time.Time `json:"resolved_at"`                               // 处理时间
+	CreatedAt  time.Time  `json:"created_at" gorm:"index"`
+}
+
+func (Report) TableName() string {
+	return "reports"
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// SchemaMigration 记录已执行的数据库迁移，每个版本一行
+type SchemaMigration struct {
+	Version   int       `json:"version" gorm:"primaryKey;autoIncrement:false"` // 迁移版本号
+	Name      string    `json:"name" gorm:"type:varchar(255)"`                 // 迁移名称
+	AppliedAt time.Time `json:"applied_at"`                                    // 执行时间
+}
+
+// TableName 指定表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag 内容标签，与内容为多对多关系，通过 content_tags 表关联
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"type:varchar(32);uniqueIndex"` // 标签名，统一为小写
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// UploadReference 记录内容对上传文件的引用，包括图片内容本身和Markdown中嵌入的图片
+// 内容的历史版本中引用过的文件也会保留引用，以便恢复旧版本时文件仍然可用
+type UploadReference struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	FilePath  string    `json:"file_path" gorm:"type:varchar(255);uniqueIndex:idx_upload_reference"` // 上传文件路径，如 uploads/xxx.png
+	ContentID uint      `json:"content_id" gorm:"uniqueIndex:idx_upload_reference;index"`            // 引用该文件的内容
+	CreatedAt time.Time `json:"created_at"`                                                          // 首次引用时间
+}
+
+// TableName 指定表名
+func (UploadReference) TableName() string {
+	return "upload_references"
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+)
+
+// User 存储注册账户信息
+type User struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Username     string    `json:"username" gorm:"type:varchar(32);uniqueIndex"` // 登录用户名
+	PasswordHash string    `json:"-" gorm:"type:varchar(255)"`                   // bcrypt 密码哈希
+	UserID       string    `json:"user_id" gorm:"type:varchar(32);uniqueIndex"`  // 账户对应的内容来源标识，与Content.Source一致
+	CreatedAt    time.Time `json:"created_at"`                                   // 注册时间
+	LastLoginAt  time.Time `json:"last_login_at"`                                // 最近登录时间
+}
+
+// TableName 指定表名
+func (User) TableName() string {
+	return "users"
+}
+
+// Session 存储服务端会话，Cookie 中只保存令牌原文，数据库保存其哈希
+type Session struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TokenHash string    `json:"-" gorm:"type:varchar(64);uniqueIndex"` // 会话令牌的SHA-256哈希
+	AccountID uint      `json:"account_id" gorm:"index"`               // 对应 User.ID
+	CreatedAt time.Time `json:"created_at"`                            // 创建时间
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`               // 过期时间
+}
+
+// TableName 指定表名
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// ClaimCode 存储用于关联其他浏览器身份的一次性认领码
+type ClaimCode struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CodeHash  string    `json:"-" gorm:"type:varchar(64);uniqueIndex"` // 认领码的SHA-256哈希
+	AccountID uint      `json:"account_id" gorm:"index"`               // 生成认领码的账户
+	CreatedAt time.Time `json:"created_at"`                            // 创建时间
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`               // 过期时间
+}
+
+// TableName 指定表名
+func (ClaimCode) TableName() string {
+	return "claim_codes"
+}
+
+// IdentityMerge 记录一次身份合并的审计信息
+type IdentityMerge struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	AccountID    uint      `json:"account_id" gorm:"index"`              // 执行合并的账户
+	FromUserID   string    `json:"from_user_id" gorm:"type:varchar(32)"` // 被合并的原用户ID
+	ToUserID     string    `json:"to_user_id" gorm:"type:varchar(32)"`   // 合并到的账户用户ID
+	ContentCount int64     `json:"content_count"`                        // 转移的内容数量
+	BrowserInfo  string    `json:"browser_info" gorm:"type:text"`        // 兑换认领码的浏览器信息
+	CreatedAt    time.Time `json:"created_at"`                           // 合并时间
+}
+
+// TableName 指定表名
+func (IdentityMerge) TableName() string {
+	return "identity_merges"
+}
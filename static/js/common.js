@@ -191,6 +191,60 @@ function simplifyMarkdown(text) {
     return text.trim();
 }
 
+// 创建标签元素，options.href 返回标签链接，options.onSelect 用于在当前页面内按标签筛选
+function createTagElement(tag, label, options = {}) {
+    const tagEl = document.createElement('a');
+    tagEl.className = 'content-tag';
+    tagEl.textContent = label;
+    
+    if (options.href) {
+        tagEl.href = options.href(tag);
+    } else {
+        tagEl.href = '#';
+    }
+    if (options.onSelect) {
+        tagEl.addEventListener('click', function(e) {
+            e.preventDefault();
+            e.stopPropagation();
+            options.onSelect(tag);
+        });
+    }
+    
+    return tagEl;
+}
+
+// 创建内容项的标签列表
+function createTagList(tags, options = {}) {
+    const tagList = document.createElement('div');
+    tagList.className = 'content-tags';
+    
+    (tags || []).forEach(tag => {
+        tagList.appendChild(createTagElement(tag, '#' + tag, options));
+    });
+    
+    return tagList;
+}
+
+// 渲染标签统计，按数量从多到少排列，activeTag 为当前筛选的标签
+function renderTagCloud(container, tagCounts, activeTag, options = {}) {
+    container.innerHTML = '';
+    
+    const tags = Object.keys(tagCounts || {}).sort((a, b) => tagCounts[b] - tagCounts[a] || a.localeCompare(b));
+    if (activeTag && !tags.includes(activeTag)) {
+        tags.unshift(activeTag);
+    }
+    
+    tags.forEach(tag => {
+        const tagEl = createTagElement(tag, `#${tag} ${tagCounts[tag] || 0}`, options);
+        if (tag === activeTag) {
+            tagEl.classList.add('active');
+        }
+        container.appendChild(tagEl);
+    });
+    
+    container.style.display = tags.length > 0 ? '' : 'none';
+}
+
 // 工具提示初始化函数
 function initTooltips() {
     const tooltipIcons = document.querySelectorAll('.tooltip-icon');
@@ -255,8 +309,8 @@ function uploadContent(formData, button, originalHTML) {
         
         // 显示成功提示
         const contentType = formData.get('type');
-        const typeText = contentType === 'markdown' ? 'Markdown' : 
-                        contentType === 'text' ? '文本' : '图片';
+        const typeTexts = { markdown: 'Markdown', text: '文本', code: '代码', image: '图片', file: '文件' };
+        const typeText = typeTexts[contentType] || '';
         showToast(`${typeText}内容分享成功！链接已复制到剪贴板`, TOAST_TYPE.SUCCESS);
         
         // 禁用输入区域
@@ -278,6 +332,8 @@ function uploadContent(formData, button, originalHTML) {
             }
         } else if (contentType === 'text') {
             document.getElementById('text-content').setAttribute('readonly', 'readonly');
+        } else if (contentType === 'code') {
+            document.getElementById('code-editor').setAttribute('readonly', 'readonly');
         } else if (contentType === 'image') {
             document.getElementById('image-selector').style.display = 'none';
         }
@@ -345,6 +401,63 @@ function toggleContentVisibility(element, contentId) {
     });
 }
 
+// 举报内容：选择举报类型并填写说明后提交
+function reportContent(shortId) {
+    fetch('/api/contents/report-categories')
+    .then(response => response.json())
+    .then(data => showModal({
+        title: '举报内容',
+        input: 'select',
+        inputOptions: data.categories,
+        inputPlaceholder: '请选择举报类型',
+        html: '<textarea id="report-detail" class="swal2-textarea" maxlength="1000" placeholder="补充说明（可选）"></textarea>',
+        showCancelButton: true,
+        confirmButtonText: '提交举报',
+        cancelButtonText: '取消',
+        inputValidator: value => !value && '请选择举报类型',
+        preConfirm: category => ({
+            category: category,
+            detail: document.getElementById('report-detail').value
+        })
+    }))
+    .then(result => {
+        if (!result.isConfirmed) {
+            return;
+        }
+
+        const formData = new FormData();
+        formData.append('category', result.value.category);
+        formData.append('detail', result.value.detail);
+
+        return fetch(`/api/contents/${shortId}/report`, {
+            method: 'POST',
+            body: formData
+        })
+        .then(response => response.json())
+        .then(data => {
+            if (data.success) {
+                showToast(data.message, TOAST_TYPE.SUCCESS);
+            } else {
+                throw new Error(data.error || '举报失败');
+            }
+        });
+    })
+    .catch(error => {
+        console.error('Error:', error);
+        showToast('举报失败: ' + error.message, TOAST_TYPE.ERROR);
+    });
+}
+
+// 绑定页面中的举报链接
+document.addEventListener('DOMContentLoaded', function() {
+    document.querySelectorAll('.report-link').forEach(link => {
+        link.addEventListener('click', function(e) {
+            e.preventDefault();
+            reportContent(this.dataset.shortId);
+        });
+    });
+});
+
 // DOM 加载完成后初始化工具提示
 document.addEventListener('DOMContentLoaded', function() {
     initTooltips();
@@ -0,0 +1,784 @@
+// 管理后台页面专用 JavaScript
+
+// 存储当前页面状态
+const adminPageSize = 20;
+let contentPage = 1;
+let contentTotal = 0;
+let collectionPage = 1;
+let collectionTotal = 0;
+let auditPage = 1;
+let auditTotal = 0;
+let reportPage = 1;
+let reportTotal = 0;
+let reportCategories = {};
+
+// 操作类型的显示名称
+const AUDIT_ACTION_LABELS = {
+    unpublish: '取消公开',
+    unlock: '解除锁定',
+    delete: '删除',
+    ban: '封禁来源',
+    unban: '解除封禁',
+    auto_hide: '自动隐藏',
+    dismiss_reports: '驳回举报',
+    uphold_reports: '确认举报'
+};
+
+// 页面加载时获取数据
+document.addEventListener('DOMContentLoaded', function() {
+    // 切换标签页
+    document.querySelectorAll('.admin-tab').forEach(tab => {
+        tab.addEventListener('click', function() {
+            switchTab(this.dataset.tab);
+        });
+    });
+
+    // 内容筛选
+    document.getElementById('filterButton').addEventListener('click', function() {
+        contentPage = 1;
+        fetchContents();
+    });
+    document.querySelectorAll('#tab-contents .search-input').forEach(input => {
+        input.addEventListener('keypress', function(e) {
+            if (e.key === 'Enter') {
+                contentPage = 1;
+                fetchContents();
+            }
+        });
+    });
+
+    // 分页
+    document.getElementById('prevPage').addEventListener('click', function() {
+        if (contentPage > 1) {
+            contentPage--;
+            fetchContents();
+        }
+    });
+    document.getElementById('nextPage').addEventListener('click', function() {
+        if (contentPage * adminPageSize < contentTotal) {
+            contentPage++;
+            fetchContents();
+        }
+    });
+
+    // 合集筛选和分页
+    document.getElementById('collectionFilterButton').addEventListener('click', function() {
+        collectionPage = 1;
+        fetchCollections();
+    });
+    document.querySelectorAll('#tab-collections .search-input').forEach(input => {
+        input.addEventListener('keypress', function(e) {
+            if (e.key === 'Enter') {
+                collectionPage = 1;
+                fetchCollections();
+            }
+        });
+    });
+    document.getElementById('collectionPrevPage').addEventListener('click', function() {
+        if (collectionPage > 1) {
+            collectionPage--;
+            fetchCollections();
+        }
+    });
+    document.getElementById('collectionNextPage').addEventListener('click', function() {
+        if (collectionPage * adminPageSize < collectionTotal) {
+            collectionPage++;
+            fetchCollections();
+        }
+    });
+
+    document.getElementById('auditPrevPage').addEventListener('click', function() {
+        if (auditPage > 1) {
+            auditPage--;
+            fetchAuditLogs();
+        }
+    });
+    document.getElementById('auditNextPage').addEventListener('click', function() {
+        if (auditPage * adminPageSize < auditTotal) {
+            auditPage++;
+            fetchAuditLogs();
+        }
+    });
+
+    document.getElementById('reportPrevPage').addEventListener('click', function() {
+        if (reportPage > 1) {
+            reportPage--;
+            fetchReports();
+        }
+    });
+    document.getElementById('reportNextPage').addEventListener('click', function() {
+        if (reportPage * adminPageSize < reportTotal) {
+            reportPage++;
+            fetchReports();
+        }
+    });
+    document.getElementById('reportStatus').addEventListener('change', function() {
+        reportPage = 1;
+        fetchReports();
+    });
+
+    // 举报类型的显示名称
+    adminRequest('/api/contents/report-categories')
+        .then(data => {
+            reportCategories = data.categories;
+        })
+        .catch(error => console.error('Error:', error));
+
+    // 封禁表单
+    document.getElementById('banButton').addEventListener('click', function() {
+        const source = document.getElementById('banSource').value.trim();
+        if (!source) {
+            showToast('请输入来源标识', TOAST_TYPE.WARNING);
+            return;
+        }
+        banSource(source, document.getElementById('banReason').value.trim(), document.getElementById('banUnpublish').checked);
+    });
+
+    fetchContents();
+});
+
+// 切换标签页并加载对应数据
+function switchTab(name) {
+    document.querySelectorAll('.admin-tab').forEach(tab => {
+        tab.classList.toggle('active', tab.dataset.tab === name);
+    });
+    document.querySelectorAll('.admin-panel').forEach(panel => {
+        panel.style.display = panel.id === 'tab-' + name ? '' : 'none';
+    });
+
+    if (name === 'contents') {
+        fetchContents();
+    } else if (name === 'collections') {
+        fetchCollections();
+    } else if (name === 'reports') {
+        fetchReports();
+    } else if (name === 'bans') {
+        fetchBans();
+    } else if (name === 'audit') {
+        fetchAuditLogs();
+    }
+}
+
+// 发送管理接口请求，返回解析后的JSON，失败时抛出错误
+function adminRequest(url, options = {}) {
+    return fetch(url, options)
+        .then(response => response.json().then(data => {
+            if (!response.ok) {
+                throw new Error(data.error || '请求失败');
+            }
+            return data;
+        }));
+}
+
+// 格式化时间
+function formatTime(value) {
+    return value ? new Date(value).toLocaleString('zh-CN') : '-';
+}
+
+// 创建表格单元格
+function createCell(text, className) {
+    const td = document.createElement('td');
+    td.textContent = text;
+    if (className) {
+        td.className = className;
+    }
+    return td;
+}
+
+// 创建操作按钮
+function createActionButton(label, onClick, danger = false) {
+    const button = document.createElement('button');
+    button.textContent = label;
+    if (danger) {
+        button.className = 'danger';
+    }
+    button.addEventListener('click', onClick);
+    return button;
+}
+
+// 弹出对话框填写操作原因，取消时返回 null
+function askReason(title, text) {
+    return showConfirm({
+        title: title,
+        text: text,
+        icon: MODAL_TYPE.WARNING,
+        input: 'text',
+        inputPlaceholder: '原因（可选）'
+    }).then(result => result.isConfirmed ? (result.value || '').trim() : null);
+}
+
+// 获取内容列表
+function fetchContents() {
+    const params = new URLSearchParams({
+        page: contentPage,
+        per_page: adminPageSize,
+        query: document.getElementById('filterQuery').value.trim(),
+        source: document.getElementById('filterSource').value.trim(),
+        tag: document.getElementById('filterTag').value.trim(),
+        type: document.getElementById('filterType').value,
+        visibility: document.getElementById('filterVisibility').value
+    });
+
+    adminRequest('/api/admin/contents?' + params.toString())
+        .then(data => {
+            contentTotal = data.total;
+            document.getElementById('contentTotal').textContent = data.total;
+            renderContents(data.items);
+            renderPager('pageInfo', contentPage, contentTotal);
+        })
+        .catch(error => {
+            console.error('Error:', error);
+            showToast('加载内容失败: ' + error.message, TOAST_TYPE.ERROR);
+        });
+}
+
+// 渲染内容列表
+function renderContents(items) {
+    const tbody = document.getElementById('contentRows');
+    tbody.innerHTML = '';
+
+    if (items.length === 0) {
+        const row = document.createElement('tr');
+        const cell = createCell('没有符合条件的内容');
+        cell.colSpan = 6;
+        row.appendChild(cell);
+        tbody.appendChild(row);
+        return;
+    }
+
+    items.forEach(item => {
+        const row = document.createElement('tr');
+
+        const linkCell = document.createElement('td');
+        const link = document.createElement('a');
+        link.href = '/' + item.short_id;
+        link.target = '_blank';
+        link.textContent = item.title || item.short_id;
+        linkCell.appendChild(link);
+        row.appendChild(linkCell);
+
+        row.appendChild(createCell(item.type));
+
+        // 点击来源按来源筛选
+        const sourceCell = createCell(item.source, 'admin-source' + (item.source_banned ? ' banned' : ''));
+        sourceCell.title = item.source_banned ? '该来源已被封禁' : '只看该来源的内容';
+        sourceCell.addEventListener('click', function() {
+            document.getElementById('filterSource').value = item.source;
+            contentPage = 1;
+            fetchContents();
+        });
+        row.appendChild(sourceCell);
+
+        const statusCell = document.createElement('td');
+        const badge = document.createElement('span');
+        badge.className = 'status-badge ' + (item.is_public ? 'public' : 'private');
+        badge.textContent = item.under_review ? '待审核' : (item.locked ? '已锁定' : (item.is_public ? '公开' : '私密'));
+        statusCell.appendChild(badge);
+        row.appendChild(statusCell);
+
+        row.appendChild(createCell(formatTime(item.createTime)));
+
+        const actionCell = document.createElement('td');
+        const actions = document.createElement('div');
+        actions.className = 'admin-actions';
+        if (item.is_public) {
+            actions.appendChild(createActionButton('取消公开', () => unpublishContent(item.short_id)));
+        }
+        if (item.locked) {
+            actions.appendChild(createActionButton('解除锁定', () => unlockContent(item.short_id)));
+        }
+        actions.appendChild(createActionButton('删除', () => deleteContent(item.short_id), true));
+        if (!item.source_banned) {
+            actions.appendChild(createActionButton('封禁来源', () => {
+                askReason('封禁来源 ' + item.source, '该来源将不能再发布内容，其所有公开内容将设为不公开')
+                    .then(reason => {
+                        if (reason !== null) {
+                            banSource(item.source, reason, true);
+                        }
+                    });
+            }, true));
+        }
+        actionCell.appendChild(actions);
+        row.appendChild(actionCell);
+
+        tbody.appendChild(row);
+    });
+}
+
+// 渲染分页信息
+function renderPager(elementId, page, total) {
+    const totalPages = Math.max(1, Math.ceil(total / adminPageSize));
+    document.getElementById(elementId).textContent = `第 ${page} / ${totalPages} 页`;
+}
+
+// 强制取消公开内容
+function unpublishContent(shortId) {
+    askReason('取消公开 ' + shortId, '内容将不再出现在公开列表中').then(reason => {
+        if (reason === null) {
+            return;
+        }
+
+        const formData = new FormData();
+        formData.append('reason', reason);
+
+        adminRequest(`/api/admin/contents/${shortId}/unpublish`, {
+            method: 'POST',
+            body: formData
+        })
+            .then(data => {
+                showToast(data.message, TOAST_TYPE.SUCCESS);
+                fetchContents();
+            })
+            .catch(error => showToast('操作失败: ' + error.message, TOAST_TYPE.ERROR));
+    });
+}
+
+// 解除强制取消公开的锁定，作者可以重新公开内容
+function unlockContent(shortId) {
+    askReason('解除锁定 ' + shortId, '内容保持不公开，作者可以重新公开').then(reason => {
+        if (reason === null) {
+            return;
+        }
+
+        const formData = new FormData();
+        formData.append('reason', reason);
+
+        adminRequest(`/api/admin/contents/${shortId}/unlock`, {
+            method: 'POST',
+            body: formData
+        })
+            .then(data => {
+                showToast(data.message, TOAST_TYPE.SUCCESS);
+                fetchContents();
+            })
+            .catch(error => showToast('操作失败: ' + error.message, TOAST_TYPE.ERROR));
+    });
+}
+
+// 强制删除内容，删除后刷新 refresh 对应的列表
+function deleteContent(shortId, refresh = fetchContents) {
+    askReason('删除 ' + shortId, '删除后无法恢复').then(reason => {
+        if (reason === null) {
+            return;
+        }
+
+        adminRequest(`/api/admin/contents/${shortId}?reason=${encodeURIComponent(reason)}`, {
+            method: 'DELETE'
+        })
+            .then(data => {
+                showToast(data.message, TOAST_TYPE.SUCCESS);
+                refresh();
+            })
+            .catch(error => showToast('删除失败: ' + error.message, TOAST_TYPE.ERROR));
+    });
+}
+
+// 获取合集列表
+function fetchCollections() {
+    const params = new URLSearchParams({
+        page: collectionPage,
+        per_page: adminPageSize,
+        query: document.getElementById('collectionQuery').value.trim(),
+        source: document.getElementById('collectionSource').value.trim(),
+        visibility: document.getElementById('collectionVisibility').value
+    });
+
+    adminRequest('/api/admin/collections?' + params.toString())
+        .then(data => {
+            collectionTotal = data.total;
+            document.getElementById('collectionTotal').textContent = data.total;
+            renderCollections(data.items);
+            renderPager('collectionPageInfo', collectionPage, collectionTotal);
+        })
+        .catch(error => {
+            console.error('Error:', error);
+            showToast('加载合集失败: ' + error.message, TOAST_TYPE.ERROR);
+        });
+}
+
+// 渲染合集列表
+function renderCollections(items) {
+    const tbody = document.getElementById('collectionRows');
+    tbody.innerHTML = '';
+
+    if (items.length === 0) {
+        const row = document.createElement('tr');
+        const cell = createCell('没有符合条件的合集');
+        cell.colSpan = 6;
+        row.appendChild(cell);
+        tbody.appendChild(row);
+        return;
+    }
+
+    items.forEach(item => {
+        const row = document.createElement('tr');
+
+        const linkCell = document.createElement('td');
+        const link = document.createElement('a');
+        link.href = '/' + item.link;
+        link.target = '_blank';
+        link.textContent = item.title || item.short_id;
+        if (item.description) {
+            link.title = item.description;
+        }
+        linkCell.appendChild(link);
+        row.appendChild(linkCell);
+
+        row.appendChild(createCell(item.item_count));
+
+        // 点击来源按来源筛选
+        const sourceCell = createCell(item.source, 'admin-source' + (item.source_banned ? ' banned' : ''));
+        sourceCell.title = item.source_banned ? '该来源已被封禁' : '只看该来源的合集';
+        sourceCell.addEventListener('click', function() {
+            document.getElementById('collectionSource').value = item.source;
+            collectionPage = 1;
+            fetchCollections();
+        });
+        row.appendChild(sourceCell);
+
+        const statusCell = document.createElement('td');
+        const badge = document.createElement('span');
+        badge.className = 'status-badge ' + (item.is_public ? 'public' : 'private');
+        badge.textContent = item.locked ? '已锁定' : (item.is_public ? '公开' : '私密');
+        statusCell.appendChild(badge);
+        row.appendChild(statusCell);
+
+        row.appendChild(createCell(formatTime(item.updateTime)));
+
+        const actionCell = document.createElement('td');
+        const actions = document.createElement('div');
+        actions.className = 'admin-actions';
+        if (item.is_public) {
+            actions.appendChild(createActionButton('取消公开', () => unpublishCollection(item.short_id)));
+        }
+        if (item.locked) {
+            actions.appendChild(createActionButton('解除锁定', () => unlockCollection(item.short_id)));
+        }
+        actions.appendChild(createActionButton('删除', () => deleteCollection(item.short_id), true));
+        actionCell.appendChild(actions);
+        row.appendChild(actionCell);
+
+        tbody.appendChild(row);
+    });
+}
+
+// 强制取消公开合集
+function unpublishCollection(shortId) {
+    askReason('取消公开合集 ' + shortId, '合集将只有创建者可以查看，解除锁定前不能重新公开').then(reason => {
+        if (reason === null) {
+            return;
+        }
+
+        const formData = new FormData();
+        formData.append('reason', reason);
+
+        adminRequest(`/api/admin/collections/${shortId}/unpublish`, {
+            method: 'POST',
+            body: formData
+        })
+            .then(data => {
+                showToast(data.message, TOAST_TYPE.SUCCESS);
+                fetchCollections();
+            })
+            .catch(error => showToast('操作失败: ' + error.message, TOAST_TYPE.ERROR));
+    });
+}
+
+// 解除合集的锁定，创建者可以重新公开合集
+function unlockCollection(shortId) {
+    askReason('解除锁定 ' + shortId, '合集保持不公开，创建者可以重新公开').then(reason => {
+        if (reason === null) {
+            return;
+        }
+
+        const formData = new FormData();
+        formData.append('reason', reason);
+
+        adminRequest(`/api/admin/collections/${shortId}/unlock`, {
+            method: 'POST',
+            body: formData
+        })
+            .then(data => {
+                showToast(data.message, TOAST_TYPE.SUCCESS);
+                fetchCollections();
+            })
+            .catch(error => showToast('操作失败: ' + error.message, TOAST_TYPE.ERROR));
+    });
+}
+
+// 强制删除合集，合集中的内容不受影响
+function deleteCollection(shortId) {
+    askReason('删除合集 ' + shortId, '合集中的内容不会被删除，删除后无法恢复').then(reason => {
+        if (reason === null) {
+            return;
+        }
+
+        adminRequest(`/api/admin/collections/${shortId}?reason=${encodeURIComponent(reason)}`, {
+            method: 'DELETE'
+        })
+            .then(data => {
+                showToast(data.message, TOAST_TYPE.SUCCESS);
+                fetchCollections();
+            })
+            .catch(error => showToast('删除失败: ' + error.message, TOAST_TYPE.ERROR));
+    });
+}
+
+// 获取被举报的内容
+function fetchReports() {
+    const status = document.getElementById('reportStatus').value;
+    adminRequest(`/api/admin/reports?status=${status}&page=${reportPage}&per_page=${adminPageSize}`)
+        .then(data => {
+            reportTotal = data.total;
+            document.getElementById('reportTotal').textContent = data.total;
+            document.getElementById('reportThreshold').textContent = data.threshold > 0 ? data.threshold : '-';
+            renderReports(data.items, status === 'open');
+            renderPager('reportPageInfo', reportPage, reportTotal);
+        })
+        .catch(error => {
+            console.error('Error:', error);
+            showToast('加载举报失败: ' + error.message, TOAST_TYPE.ERROR);
+        });
+}
+
+// 渲染被举报的内容，待处理的举报可以驳回或确认
+function renderReports(items, pending) {
+    const tbody = document.getElementById('reportRows');
+    tbody.innerHTML = '';
+
+    if (items.length === 0) {
+        const row = document.createElement('tr');
+        const cell = createCell('没有举报');
+        cell.colSpan = 6;
+        row.appendChild(cell);
+        tbody.appendChild(row);
+        return;
+    }
+
+    items.forEach(item => {
+        const row = document.createElement('tr');
+
+        const linkCell = document.createElement('td');
+        const link = document.createElement('a');
+        link.href = '/' + item.short_id;
+        link.target = '_blank';
+        link.textContent = item.title || item.short_id;
+        linkCell.appendChild(link);
+        row.appendChild(linkCell);
+
+        row.appendChild(createCell(item.source, 'admin-source'));
+
+        const statusCell = document.createElement('td');
+        const badge = document.createElement('span');
+        badge.className = 'status-badge ' + (item.is_public ? 'public' : 'private');
+        badge.textContent = item.under_review ? '待审核' : (item.is_public ? '公开' : '私密');
+        statusCell.appendChild(badge);
+        row.appendChild(statusCell);
+
+        // 按类型汇总的举报数量
+        const categoryCell = document.createElement('td');
+        const categories = document.createElement('div');
+        categories.className = 'admin-categories';
+        Object.entries(item.categories).forEach(([category, count]) => {
+            const span = document.createElement('span');
+            span.textContent = `${reportCategories[category] || category} ×${count}`;
+            categories.appendChild(span);
+        });
+        categoryCell.appendChild(categories);
+        row.appendChild(categoryCell);
+
+        row.appendChild(createCell(formatTime(item.last_reported_at)));
+
+        const actionCell = document.createElement('td');
+        const actions = document.createElement('div');
+        actions.className = 'admin-actions';
+        actions.appendChild(createActionButton('详情', () => showContentReports(item.short_id)));
+        if (pending) {
+            actions.appendChild(createActionButton('驳回', () => resolveReports(item.short_id, 'dismiss')));
+            actions.appendChild(createActionButton('确认', () => resolveReports(item.short_id, 'uphold'), true));
+            actions.appendChild(createActionButton('删除', () => deleteContent(item.short_id, fetchReports), true));
+        }
+        actionCell.appendChild(actions);
+        row.appendChild(actionCell);
+
+        tbody.appendChild(row);
+    });
+}
+
+// 显示内容收到的所有举报
+function showContentReports(shortId) {
+    adminRequest('/api/admin/reports/' + shortId)
+        .then(data => {
+            const list = document.createElement('ol');
+            list.className = 'admin-report-list';
+            data.items.forEach(report => {
+                const entry = document.createElement('li');
+                const category = reportCategories[report.category] || report.category;
+                entry.textContent = `[${category}] ${formatTime(report.created_at)} ${report.reporter}` +
+                    (report.reporter_ip ? ` (${report.reporter_ip})` : '') +
+                    (report.detail ? `：${report.detail}` : '') +
+                    (report.status !== 'open' ? `（${report.status === 'upheld' ? '已确认' : '已驳回'}，${report.resolved_by}）` : '');
+                list.appendChild(entry);
+            });
+
+            showModal({
+                title: shortId + ' 的举报',
+                html: list,
+                width: 640
+            });
+        })
+        .catch(error => showToast('加载举报失败: ' + error.message, TOAST_TYPE.ERROR));
+}
+
+// 处理内容的待处理举报
+function resolveReports(shortId, decision) {
+    const text = decision === 'dismiss'
+        ? '举报不成立，被自动隐藏的内容将恢复公开'
+        : '举报成立，内容将设为不公开';
+    askReason(decision === 'dismiss' ? '驳回举报' : '确认举报', text).then(note => {
+        if (note === null) {
+            return;
+        }
+
+        const formData = new FormData();
+        formData.append('decision', decision);
+        formData.append('note', note);
+
+        adminRequest(`/api/admin/reports/${shortId}/resolve`, {
+            method: 'POST',
+            body: formData
+        })
+            .then(data => {
+                showToast(data.message, TOAST_TYPE.SUCCESS);
+                fetchReports();
+            })
+            .catch(error => showToast('操作失败: ' + error.message, TOAST_TYPE.ERROR));
+    });
+}
+
+// 获取封禁列表
+function fetchBans() {
+    adminRequest('/api/admin/bans')
+        .then(data => renderBans(data.items))
+        .catch(error => {
+            console.error('Error:', error);
+            showToast('加载封禁列表失败: ' + error.message, TOAST_TYPE.ERROR);
+        });
+}
+
+// 渲染封禁列表
+function renderBans(items) {
+    const tbody = document.getElementById('banRows');
+    tbody.innerHTML = '';
+
+    if (items.length === 0) {
+        const row = document.createElement('tr');
+        const cell = createCell('没有被封禁的来源');
+        cell.colSpan = 6;
+        row.appendChild(cell);
+        tbody.appendChild(row);
+        return;
+    }
+
+    items.forEach(item => {
+        const row = document.createElement('tr');
+        row.appendChild(createCell(item.source, 'admin-source banned'));
+        row.appendChild(createCell(item.reason || '-'));
+        row.appendChild(createCell(item.content_count));
+        row.appendChild(createCell(item.created_by));
+        row.appendChild(createCell(formatTime(item.created_at)));
+
+        const actionCell = document.createElement('td');
+        const actions = document.createElement('div');
+        actions.className = 'admin-actions';
+        actions.appendChild(createActionButton('解除封禁', () => unbanSource(item.source)));
+        actionCell.appendChild(actions);
+        row.appendChild(actionCell);
+
+        tbody.appendChild(row);
+    });
+}
+
+// 封禁来源
+function banSource(source, reason, unpublish) {
+    const formData = new FormData();
+    formData.append('source', source);
+    formData.append('reason', reason);
+    formData.append('unpublish', unpublish ? 'true' : 'false');
+
+    adminRequest('/api/admin/bans', {
+        method: 'POST',
+        body: formData
+    })
+        .then(data => {
+            showToast(data.message, TOAST_TYPE.SUCCESS);
+            document.getElementById('banSource').value = '';
+            document.getElementById('banReason').value = '';
+            if (document.getElementById('tab-bans').style.display === 'none') {
+                fetchContents();
+            } else {
+                fetchBans();
+            }
+        })
+        .catch(error => showToast('封禁失败: ' + error.message, TOAST_TYPE.ERROR));
+}
+
+// 解除封禁
+function unbanSource(source) {
+    showConfirm({
+        title: '解除封禁',
+        text: `确定允许来源 ${source} 重新发布内容吗？`
+    }).then(result => {
+        if (!result.isConfirmed) {
+            return;
+        }
+
+        adminRequest('/api/admin/bans/' + encodeURIComponent(source), {
+            method: 'DELETE'
+        })
+            .then(data => {
+                showToast(data.message, TOAST_TYPE.SUCCESS);
+                fetchBans();
+            })
+            .catch(error => showToast('操作失败: ' + error.message, TOAST_TYPE.ERROR));
+    });
+}
+
+// 获取审计日志
+function fetchAuditLogs() {
+    adminRequest(`/api/admin/audit?page=${auditPage}&per_page=${adminPageSize}`)
+        .then(data => {
+            auditTotal = data.total;
+            renderAuditLogs(data.items);
+            renderPager('auditPageInfo', auditPage, auditTotal);
+        })
+        .catch(error => {
+            console.error('Error:', error);
+            showToast('加载审计日志失败: ' + error.message, TOAST_TYPE.ERROR);
+        });
+}
+
+// 渲染审计日志
+function renderAuditLogs(items) {
+    const tbody = document.getElementById('auditRows');
+    tbody.innerHTML = '';
+
+    if (items.length === 0) {
+        const row = document.createElement('tr');
+        const cell = createCell('暂无操作记录');
+        cell.colSpan = 5;
+        row.appendChild(cell);
+        tbody.appendChild(row);
+        return;
+    }
+
+    items.forEach(item => {
+        const row = document.createElement('tr');
+        row.appendChild(createCell(formatTime(item.created_at)));
+        row.appendChild(createCell(item.actor));
+        row.appendChild(createCell(AUDIT_ACTION_LABELS[item.action] || item.action));
+        row.appendChild(createCell(`${item.target_type}:${item.target_id}`));
+        row.appendChild(createCell(item.detail || '-'));
+        tbody.appendChild(row);
+    });
+}
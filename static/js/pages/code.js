@@ -0,0 +1,63 @@
+// 代码内容页面专用 JavaScript
+
+// 最近一次点击的行号，用于按住 Shift 选择行范围
+let lastSelectedLine = null;
+
+document.addEventListener('DOMContentLoaded', function() {
+    // 复制代码
+    document.getElementById('copy-button').addEventListener('click', function() {
+        const content = document.getElementById('content').value;
+        copyToClipboard(content);
+    });
+    
+    // 点击行号选中该行，按住 Shift 点击选中一个范围
+    document.querySelectorAll('#code-view .lnlinks').forEach(link => {
+        link.addEventListener('click', function(e) {
+            const line = parseInt(this.getAttribute('href').substring(2), 10);
+            if (e.shiftKey && lastSelectedLine !== null) {
+                e.preventDefault();
+                const start = Math.min(lastSelectedLine, line);
+                const end = Math.max(lastSelectedLine, line);
+                history.replaceState(null, '', start === end ? `#L${start}` : `#L${start}-L${end}`);
+                highlightLinesFromHash(false);
+                return;
+            }
+            lastSelectedLine = line;
+        });
+    });
+    
+    window.addEventListener('hashchange', function() {
+        highlightLinesFromHash(false);
+    });
+    highlightLinesFromHash(true);
+});
+
+// 根据地址中的 #L10 或 #L10-L20 高亮对应的行
+function highlightLinesFromHash(scroll) {
+    const codeLines = document.querySelectorAll('#code-view code > .line');
+    const lineNumbers = document.querySelectorAll('#code-view .lnt');
+    
+    codeLines.forEach(line => line.classList.remove('hl'));
+    lineNumbers.forEach(line => line.classList.remove('hl'));
+    
+    const match = window.location.hash.match(/^#L(\d+)(?:-L(\d+))?$/);
+    if (!match) {
+        return;
+    }
+    
+    let start = parseInt(match[1], 10);
+    let end = match[2] ? parseInt(match[2], 10) : start;
+    if (start > end) {
+        [start, end] = [end, start];
+    }
+    
+    for (let i = start; i <= end && i <= codeLines.length; i++) {
+        codeLines[i - 1].classList.add('hl');
+        lineNumbers[i - 1].classList.add('hl');
+    }
+    lastSelectedLine = start;
+    
+    if (scroll && lineNumbers[start - 1]) {
+        lineNumbers[start - 1].scrollIntoView({ block: 'center' });
+    }
+}
@@ -54,6 +54,11 @@ function initPage() {
         initMarkdownEditor();
     }
     
+    // 初始化代码语言选择
+    if (contentType === 'code') {
+        initLanguageSelect();
+    }
+    
     // 绑定保存按钮点击事件
     const saveButton = document.getElementById('saveButton');
     if (saveButton) {
@@ -72,6 +77,20 @@ function initPage() {
     }
 }
 
+// 初始化代码语言选择，选中当前语言，不在列表中的语言追加为选项
+function initLanguageSelect() {
+    const select = document.getElementById('language');
+    if (!select) return;
+    
+    const current = select.getAttribute('data-current');
+    if (!current) return;
+    
+    if (!Array.from(select.options).some(option => option.value === current)) {
+        select.add(new Option(current, current));
+    }
+    select.value = current;
+}
+
 // 初始化Markdown编辑器
 function initMarkdownEditor() {
     if(contentType !== 'markdown') return;
@@ -228,6 +247,25 @@ function saveContent(e) {
     formData.append('title', title);
     formData.append('type', type);
     formData.append('is_public', isPublic ? 'true' : 'false');
+    formData.append('tags', document.getElementById('tags').value);
+
+    // 有效期和访问次数只在修改时提交
+    const expiresIn = document.getElementById('expiresIn').value;
+    if (expiresIn) {
+        formData.append('expires_in', expiresIn);
+    }
+    const maxViewsInput = document.getElementById('maxViews');
+    if (maxViewsInput.value !== maxViewsInput.dataset.original) {
+        formData.append('max_views', maxViewsInput.value || '0');
+    }
+
+    // 访问密码
+    const removePassword = document.getElementById('removePassword');
+    if (removePassword && removePassword.checked) {
+        formData.append('remove_password', 'true');
+    } else if (document.getElementById('password').value) {
+        formData.append('password', document.getElementById('password').value);
+    }
     
     // 根据内容类型获取内容
     if (contentType === 'markdown') {
@@ -238,6 +276,9 @@ function saveContent(e) {
         }
     } else if (contentType === 'text') {
         formData.append('content', document.getElementById('content').value);
+    } else if (contentType === 'code') {
+        formData.append('content', document.getElementById('content').value);
+        formData.append('language', document.getElementById('language').value);
     } else if (contentType === 'image') {
         // 图片类型不需要发送content字段，服务器会保留原始图片路径
     }
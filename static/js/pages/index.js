@@ -17,6 +17,9 @@ document.addEventListener('DOMContentLoaded', function() {
     // 初始化图片上传预览
     initImageUploadPreview();
     
+    // 初始化文件选择
+    initFileUpload();
+    
     // 初始化各种按钮事件
     initButtonEvents();
     
@@ -221,6 +224,33 @@ function initImageUploadPreview() {
     });
 }
 
+// 初始化文件选择
+function initFileUpload() {
+    document.getElementById('file-upload').addEventListener('change', function(event) {
+        const file = event.target.files[0];
+        const info = document.getElementById('file-selected-info');
+        if (file) {
+            info.textContent = `已选择: ${file.name} (${formatFileSize(file.size)})`;
+            info.classList.remove('hidden');
+            document.getElementById('share-file').disabled = false;
+        } else {
+            info.classList.add('hidden');
+            document.getElementById('share-file').disabled = true;
+        }
+    });
+}
+
+// 格式化文件大小
+function formatFileSize(size) {
+    const units = ['B', 'KB', 'MB', 'GB'];
+    let index = 0;
+    while (size >= 1024 && index < units.length - 1) {
+        size /= 1024;
+        index++;
+    }
+    return index === 0 ? `${size} ${units[index]}` : `${size.toFixed(1)} ${units[index]}`;
+}
+
 // 初始化按钮事件
 function initButtonEvents() {
     // 继续分享按钮事件
@@ -257,6 +287,7 @@ function initButtonEvents() {
             formData.append('title', title);
         }
         formData.append('is_public', isPublic ? 'true' : 'false');
+        appendShareOptions(formData);
         
         uploadContent(formData, button, originalHTML);
     });
@@ -284,6 +315,36 @@ function initButtonEvents() {
             formData.append('title', title);
         }
         formData.append('is_public', isPublic ? 'true' : 'false');
+        appendShareOptions(formData);
+        
+        uploadContent(formData, button, originalHTML);
+    });
+    
+    // 代码分享
+    document.getElementById('share-code').addEventListener('click', function() {
+        const button = this;
+        const originalHTML = button.innerHTML;
+        
+        const codeContent = document.getElementById('code-editor').value;
+        if (!codeContent.trim()) {
+            showToast('请输入要分享的代码');
+            return;
+        }
+        
+        disableButton(button);
+        
+        const title = document.getElementById('content-title').value.trim();
+        const isPublic = document.getElementById('is-public').checked;
+        
+        const formData = new FormData();
+        formData.append('content', codeContent);
+        formData.append('type', 'code');
+        formData.append('language', document.getElementById('code-language').value);
+        if (title) {
+            formData.append('title', title);
+        }
+        formData.append('is_public', isPublic ? 'true' : 'false');
+        appendShareOptions(formData);
         
         uploadContent(formData, button, originalHTML);
     });
@@ -311,11 +372,63 @@ function initButtonEvents() {
             formData.append('title', title);
         }
         formData.append('is_public', isPublic ? 'true' : 'false');
+        appendShareOptions(formData);
+        
+        uploadContent(formData, button, originalHTML);
+    });
+    
+    // 文件分享
+    document.getElementById('share-file').addEventListener('click', function() {
+        const button = this;
+        const originalHTML = button.innerHTML;
+        
+        const file = document.getElementById('file-upload').files[0];
+        if (!file) {
+            showToast('请选择要分享的文件');
+            return;
+        }
+        
+        disableButton(button);
+        
+        const title = document.getElementById('content-title').value.trim();
+        const isPublic = document.getElementById('is-public').checked;
+        
+        const formData = new FormData();
+        formData.append('file', file);
+        formData.append('type', 'file');
+        if (title) {
+            formData.append('title', title);
+        }
+        formData.append('is_public', isPublic ? 'true' : 'false');
+        appendShareOptions(formData);
         
         uploadContent(formData, button, originalHTML);
     });
 }
 
+// 添加有效期、访问次数、访问密码等分享选项
+function appendShareOptions(formData) {
+    const expiresIn = document.getElementById('expires-in').value;
+    if (expiresIn) {
+        formData.append('expires_in', expiresIn);
+    }
+
+    const maxViews = document.getElementById('max-views').value;
+    if (maxViews) {
+        formData.append('max_views', maxViews);
+    }
+
+    const password = document.getElementById('content-password').value;
+    if (password) {
+        formData.append('password', password);
+    }
+
+    const tags = document.getElementById('content-tags').value.trim();
+    if (tags) {
+        formData.append('tags', tags);
+    }
+}
+
 // 初始化社交分享
 function initSocialSharing() {
     // 微信分享
@@ -345,6 +458,7 @@ function resetForm() {
     // 清除标题
     document.getElementById('content-title').value = '';
     document.getElementById('content-title').removeAttribute('readonly');
+    document.getElementById('content-tags').value = '';
     
     // 重置 Markdown 编辑器
     easyMDE.value('');
@@ -365,12 +479,22 @@ function resetForm() {
     document.getElementById('text-editor').value = '';
     document.getElementById('text-editor').removeAttribute('readonly');
     
+    // 重置代码编辑器
+    document.getElementById('code-editor').value = '';
+    document.getElementById('code-editor').removeAttribute('readonly');
+    document.getElementById('code-language').value = 'auto';
+    
     // 重置图片上传
     document.getElementById('image-upload').value = '';
     document.getElementById('preview-image').src = '#';
     document.getElementById('image-preview-wrapper').classList.add('hidden');
     document.getElementById('share-image').disabled = true;
     
+    // 重置文件上传
+    document.getElementById('file-upload').value = '';
+    document.getElementById('file-selected-info').classList.add('hidden');
+    document.getElementById('share-file').disabled = true;
+    
     // 重置公开设置复选框
     const isPublicCheckbox = document.getElementById('is-public');
     isPublicCheckbox.disabled = false;
@@ -390,7 +514,9 @@ function resetForm() {
     // 重置分享按钮文本
     document.getElementById('share-md').innerHTML = '<i class="fas fa-share-alt"></i> 分享内容';
     document.getElementById('share-text').innerHTML = '<i class="fas fa-share-alt"></i> 分享内容';
+    document.getElementById('share-code').innerHTML = '<i class="fas fa-share-alt"></i> 分享代码';
     document.getElementById('share-image').innerHTML = '<i class="fas fa-share-alt"></i> 分享图片';
+    document.getElementById('share-file').innerHTML = '<i class="fas fa-share-alt"></i> 分享文件';
     
     // 隐藏结果区域
     document.getElementById('result').classList.add('hidden');
@@ -0,0 +1,164 @@
+// 账户页面专用 JavaScript
+
+document.addEventListener('DOMContentLoaded', function() {
+    // 登录/注册表单切换
+    document.querySelectorAll('.tab[data-form]').forEach(tab => {
+        tab.addEventListener('click', function() {
+            document.querySelectorAll('.tab[data-form]').forEach(t => t.classList.remove('active'));
+            this.classList.add('active');
+
+            document.querySelectorAll('.account-panel').forEach(form => form.classList.add('hidden'));
+            document.getElementById(this.dataset.form).classList.remove('hidden');
+        });
+    });
+
+    const loginForm = document.getElementById('login-form');
+    if (loginForm) {
+        loginForm.addEventListener('submit', function(e) {
+            e.preventDefault();
+            submitAccountForm('/api/auth/login', new FormData(loginForm));
+        });
+    }
+
+    const registerForm = document.getElementById('register-form');
+    if (registerForm) {
+        registerForm.addEventListener('submit', function(e) {
+            e.preventDefault();
+            const formData = new FormData(registerForm);
+            formData.append('claim_browser', document.getElementById('claim-browser').checked ? 'true' : 'false');
+            submitAccountForm('/api/auth/register', formData);
+        });
+    }
+
+    const claimForm = document.getElementById('claim-form');
+    if (claimForm) {
+        claimForm.addEventListener('submit', function(e) {
+            e.preventDefault();
+            submitAccountForm('/api/auth/claim', new FormData(claimForm));
+        });
+    }
+
+    const claimCodeButton = document.getElementById('claim-code-button');
+    if (claimCodeButton) {
+        claimCodeButton.addEventListener('click', createClaimCode);
+    }
+
+    const tokenForm = document.getElementById('token-form');
+    if (tokenForm) {
+        tokenForm.addEventListener('submit', function(e) {
+            e.preventDefault();
+            createAPIToken(new FormData(tokenForm));
+        });
+        loadAPITokens();
+    }
+
+    const logoutButton = document.getElementById('logout-button');
+    if (logoutButton) {
+        logoutButton.addEventListener('click', function() {
+            submitAccountForm('/api/auth/logout', new FormData());
+        });
+    }
+});
+
+// 提交账户表单，成功后刷新页面
+function submitAccountForm(url, formData) {
+    fetch(url, {
+        method: 'POST',
+        body: formData
+    })
+    .then(response => response.json())
+    .then(data => {
+        if (data.error) {
+            throw new Error(data.error);
+        }
+        showToast(data.message, TOAST_TYPE.SUCCESS);
+        setTimeout(() => window.location.reload(), 800);
+    })
+    .catch(error => {
+        showToast(error.message, TOAST_TYPE.ERROR);
+    });
+}
+
+// 生成认领码并显示
+function createClaimCode() {
+    fetch('/api/auth/claim-codes', {
+        method: 'POST'
+    })
+    .then(response => response.json())
+    .then(data => {
+        if (data.error) {
+            throw new Error(data.error);
+        }
+        const codeElement = document.getElementById('claim-code');
+        codeElement.textContent = data.code;
+        codeElement.classList.remove('hidden');
+        copyToClipboard(data.code);
+    })
+    .catch(error => {
+        showToast(error.message, TOAST_TYPE.ERROR);
+    });
+}
+
+// 加载API令牌列表
+function loadAPITokens() {
+    fetch('/api/tokens')
+    .then(response => response.json())
+    .then(data => {
+        const list = document.getElementById('token-list');
+        list.innerHTML = '';
+        (data.items || []).forEach(token => {
+            const item = document.createElement('li');
+            const lastUsed = token.last_used_at && !token.last_used_at.startsWith('0001')
+                ? new Date(token.last_used_at).toLocaleString() : '从未使用';
+            item.textContent = `${token.name} (${token.prefix}…) [${token.scopes}] 最近使用: ${lastUsed} `;
+
+            const revokeButton = document.createElement('button');
+            revokeButton.className = 'button';
+            revokeButton.innerHTML = '<i class="fas fa-trash"></i> 吊销';
+            revokeButton.addEventListener('click', () => revokeAPIToken(token.id));
+            item.appendChild(revokeButton);
+
+            list.appendChild(item);
+        });
+    });
+}
+
+// 创建API令牌，令牌原文只显示一次
+function createAPIToken(formData) {
+    fetch('/api/tokens', {
+        method: 'POST',
+        body: formData
+    })
+    .then(response => response.json())
+    .then(data => {
+        if (data.error) {
+            throw new Error(data.error);
+        }
+        const tokenElement = document.getElementById('new-token');
+        tokenElement.textContent = data.token;
+        tokenElement.classList.remove('hidden');
+        showToast(data.message, TOAST_TYPE.SUCCESS);
+        loadAPITokens();
+    })
+    .catch(error => {
+        showToast(error.message, TOAST_TYPE.ERROR);
+    });
+}
+
+// 吊销API令牌
+function revokeAPIToken(tokenId) {
+    fetch(`/api/tokens/${tokenId}`, {
+        method: 'DELETE'
+    })
+    .then(response => response.json())
+    .then(data => {
+        if (data.error) {
+            throw new Error(data.error);
+        }
+        showToast(data.message, TOAST_TYPE.SUCCESS);
+        loadAPITokens();
+    })
+    .catch(error => {
+        showToast(error.message, TOAST_TYPE.ERROR);
+    });
+}
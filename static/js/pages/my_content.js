@@ -6,6 +6,7 @@ let pageSize = 10;
 let totalItems = 0;
 let searchTerm = '';
 let typeFilter = 'all'; // 内容类型筛选
+let tagFilter = ''; // 标签筛选
 
 // 页面加载时获取数据
 document.addEventListener('DOMContentLoaded', function() {
@@ -46,6 +47,13 @@ window.filterByType = function(type) {
     }
 };
 
+// 通过标签筛选内容，再次点击当前标签取消筛选
+window.filterByTag = function(tag) {
+    tagFilter = tag === tagFilter ? '' : tag;
+    currentPage = 1; // 重置到第一页
+    fetchContentPage(currentPage);
+};
+
 // 更新类型筛选器的激活状态
 function updateTypeFilterActiveState() {
     // 移除所有类型筛选器的激活状态
@@ -76,6 +84,9 @@ function fetchContentPage(page) {
     if (typeFilter !== 'all') {
         params.append('type', typeFilter);
     }
+    if (tagFilter) {
+        params.append('tag', tagFilter);
+    }
     
     // 记录请求参数用于调试
     console.log('API请求参数:', params.toString());
@@ -137,14 +148,23 @@ function displayContent(data) {
     if (data.typeCounts) {
         document.getElementById('markdownCount').textContent = data.typeCounts.markdown || 0;
         document.getElementById('textCount').textContent = data.typeCounts.text || 0;
+        document.getElementById('codeCount').textContent = data.typeCounts.code || 0;
         document.getElementById('imageCount').textContent = data.typeCounts.image || 0;
+        document.getElementById('fileCount').textContent = data.typeCounts.file || 0;
     } else {
         // 如果后端未提供类型统计，暂时清空计数
         document.getElementById('markdownCount').textContent = '0';
         document.getElementById('textCount').textContent = '0';
+        document.getElementById('codeCount').textContent = '0';
         document.getElementById('imageCount').textContent = '0';
+        document.getElementById('fileCount').textContent = '0';
     }
     
+    // 更新标签统计
+    renderTagCloud(document.getElementById('tagCloud'), data.tagCounts, tagFilter, {
+        onSelect: filterByTag
+    });
+    
     // 显示或隐藏相关元素
     if (totalItems > 0) {
         // 有内容，渲染当前页内容
@@ -421,8 +441,12 @@ function renderContent(items) {
                 previewText = simplifyMarkdown(previewText);
             }
             
-            // 设置纯文本内容，更安全
-            summaryDiv.textContent = previewText;
+            // 设置纯文本内容，更安全；搜索结果的高亮摘要已由服务端转义
+            if (item.snippet) {
+                summaryDiv.innerHTML = item.snippet;
+            } else {
+                summaryDiv.textContent = previewText;
+            }
             
             previewContainer.appendChild(summaryDiv);
             
@@ -457,6 +481,12 @@ function renderContent(items) {
         } else if (item.type === 'image') {
             typeIcon = '<i class="fas fa-image type-icon"></i>';
             typeTitle = '图片内容';
+        } else if (item.type === 'code') {
+            typeIcon = '<i class="fas fa-code type-icon"></i>';
+            typeTitle = '代码内容' + (item.language ? ` (${item.language})` : '');
+        } else if (item.type === 'file') {
+            typeIcon = '<i class="fas fa-file type-icon"></i>';
+            typeTitle = '文件内容';
         } else {
             typeIcon = '<i class="fas fa-file-alt type-icon"></i>';
             typeTitle = '文本内容';
@@ -473,6 +503,17 @@ function renderContent(items) {
         const statusBadge = document.createElement('span');
         statusBadge.className = `status-badge ${item.is_public ? 'public' : 'private'}`;
         statusBadge.textContent = item.is_public ? '公开' : '私密';
+        if (item.under_review) {
+            // 因举报被隐藏的内容在审核完成前不能重新公开
+            statusBadge.className = 'status-badge private';
+            statusBadge.textContent = '审核中';
+            statusBadge.title = '该内容因被多次举报已自动隐藏，等待管理员审核';
+        } else if (item.locked) {
+            // 被管理员取消公开的内容在解除锁定前不能重新公开
+            statusBadge.className = 'status-badge private';
+            statusBadge.textContent = '已锁定';
+            statusBadge.title = '该内容已被管理员取消公开，暂时不能重新公开';
+        }
         
         visibilitySpan.appendChild(statusBadge);
         visibilitySpan.dataset.id = item.short_id;
@@ -511,10 +552,11 @@ function renderContent(items) {
         const editSpan = document.createElement('span');
         
         // 根据内容类型设置不同的样式和行为
-        if (item.type === 'image') {
-            // 图片内容的编辑按钮显示为禁用状态
+        if (item.type === 'image' || item.type === 'file') {
+            // 图片和文件内容的编辑按钮显示为禁用状态
+            const typeName = item.type === 'image' ? '图片' : '文件';
             editSpan.className = 'meta-item edit-item disabled';
-            editSpan.title = '图片内容暂不支持编辑';
+            editSpan.title = typeName + '内容暂不支持编辑';
             editSpan.innerHTML = '<i class="fas fa-edit"></i>';
             editSpan.style.cursor = 'not-allowed';
             editSpan.style.opacity = '0.5';
@@ -522,7 +564,7 @@ function renderContent(items) {
             // 添加点击事件，显示提示
             editSpan.addEventListener('click', function(e) {
                 e.stopPropagation();
-                showToast('图片内容暂不支持编辑功能', TOAST_TYPE.INFO);
+                showToast(typeName + '内容暂不支持编辑功能', TOAST_TYPE.INFO);
             });
         } else {
             // 文本/Markdown内容的编辑按钮正常显示
@@ -561,6 +603,9 @@ function renderContent(items) {
         // 组装内容项
         li.appendChild(title);
         li.appendChild(previewContainer);
+        if (item.tags && item.tags.length > 0) {
+            li.appendChild(createTagList(item.tags, { onSelect: filterByTag }));
+        }
         li.appendChild(actions);
         li.appendChild(metaDiv);
         
@@ -7,18 +7,25 @@ let totalItems = 0;
 let currentType = 'all';
 let searchTerm = '';
 let typeStats = {};
+let tagStats = {};
+let currentTag = ''; // 标签页面 /tags/:tag 只显示带有该标签的内容
 
 // 页面加载完成后执行
 document.addEventListener('DOMContentLoaded', function() {
+    currentTag = document.body.dataset.tag || '';
+    
     // 初始加载内容
     fetchPublicContent(currentPage);
     
     // 设置类型筛选器事件监听
-    document.getElementById('type-filter').addEventListener('change', function() {
-        currentType = this.value;
-        currentPage = 1; // 重置为第一页
-        fetchPublicContent(currentPage);
-    });
+    const typeFilterSelect = document.getElementById('type-filter');
+    if (typeFilterSelect) {
+        typeFilterSelect.addEventListener('change', function() {
+            currentType = this.value;
+            currentPage = 1; // 重置为第一页
+            fetchPublicContent(currentPage);
+        });
+    }
     
     // 设置搜索按钮事件监听
     document.getElementById('searchButton').addEventListener('click', function() {
@@ -92,6 +99,11 @@ function fetchPublicContent(page) {
         params.append('query', searchTerm);
     }
     
+    // 添加标签过滤
+    if (currentTag) {
+        params.append('tag', currentTag);
+    }
+    
     // 发送请求 - 使用新的API路径
     fetch(`/api/contents/public?${params.toString()}`)
         .then(response => {
@@ -110,10 +122,16 @@ function fetchPublicContent(page) {
             totalItems = data.total || 0;
             pageSize = data.per_page || 10;
             typeStats = data.typeCounts || {};
+            tagStats = data.tagCounts || {};
             
             // 更新类型统计数量
             updateTypeStats();
             
+            // 更新标签统计，点击当前标签返回全部公开内容
+            renderTagCloud(document.getElementById('tagCloud'), tagStats, currentTag, {
+                href: tag => tag === currentTag ? '/public' : '/tags/' + encodeURIComponent(tag)
+            });
+            
             // 处理内容渲染
             displayContent(data);
             
@@ -134,7 +152,9 @@ function updateTypeStats() {
     document.getElementById('contentCount').textContent = totalItems;
     document.getElementById('markdownCount').textContent = typeStats.markdown || 0;
     document.getElementById('textCount').textContent = typeStats.text || 0;
+    document.getElementById('codeCount').textContent = typeStats.code || 0;
     document.getElementById('imageCount').textContent = typeStats.image || 0;
+    document.getElementById('fileCount').textContent = typeStats.file || 0;
 }
 
 // 获取类型标签
@@ -142,7 +162,9 @@ function getTypeLabel(type) {
     switch (type) {
         case 'text': return '文本';
         case 'markdown': return 'Markdown';
+        case 'code': return '代码';
         case 'image': return '图片';
+        case 'file': return '文件';
         default: return type;
     }
 }
@@ -268,8 +290,12 @@ function createContentItem(item) {
             previewText = simplifyMarkdown(previewText);
         }
         
-        // 设置纯文本内容，更安全
-        summaryDiv.textContent = previewText;
+        // 设置纯文本内容，更安全；搜索结果的高亮摘要已由服务端转义
+        if (item.snippet) {
+            summaryDiv.innerHTML = item.snippet;
+        } else {
+            summaryDiv.textContent = previewText;
+        }
         
         previewContainer.appendChild(summaryDiv);
     }
@@ -288,6 +314,12 @@ function createContentItem(item) {
     } else if (item.type === 'image') {
         typeIcon.innerHTML = '<i class="fas fa-image"></i>';
         typeIcon.title = '图片内容';
+    } else if (item.type === 'code') {
+        typeIcon.innerHTML = '<i class="fas fa-code"></i>';
+        typeIcon.title = '代码内容' + (item.language ? ` (${item.language})` : '');
+    } else if (item.type === 'file') {
+        typeIcon.innerHTML = '<i class="fas fa-file"></i>';
+        typeIcon.title = '文件内容';
     } else {
         typeIcon.innerHTML = '<i class="fas fa-file-alt"></i>';
         typeIcon.title = '文本内容';
@@ -311,9 +343,26 @@ function createContentItem(item) {
     timeSpan.innerHTML = `<i class="far fa-clock"></i> ${formattedDate}`;
     metaDiv.appendChild(timeSpan);
     
+    // 添加举报按钮
+    const reportLink = document.createElement('a');
+    reportLink.href = '#';
+    reportLink.className = 'meta-item report-link';
+    reportLink.title = '举报该内容';
+    reportLink.innerHTML = '<i class="fas fa-flag"></i>';
+    reportLink.addEventListener('click', function(e) {
+        e.preventDefault();
+        reportContent(item.short_id);
+    });
+    metaDiv.appendChild(reportLink);
+    
     // 将所有元素添加到内容项
     contentItem.appendChild(titleEl);
     contentItem.appendChild(previewContainer);
+    if (item.tags && item.tags.length > 0) {
+        contentItem.appendChild(createTagList(item.tags, {
+            href: tag => '/tags/' + encodeURIComponent(tag)
+        }));
+    }
     contentItem.appendChild(metaDiv);
     
     return contentItem;
@@ -401,8 +401,12 @@ function createContentItem(item) {
             previewText = simplifyMarkdown(previewText);
         }
         
-        // 设置纯文本内容，更安全
-        summaryDiv.textContent = previewText;
+        // 设置纯文本内容，更安全；搜索结果的高亮摘要已由服务端转义
+        if (item.snippet) {
+            summaryDiv.innerHTML = item.snippet;
+        } else {
+            summaryDiv.textContent = previewText;
+        }
         
         previewContainer.appendChild(summaryDiv);
     }
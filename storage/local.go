@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Local 基于本地文件系统的存储后端
+type Local struct {
+	dir string
+}
+
+// NewLocal 创建本地文件系统存储，目录不存在时自动创建
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建存储目录失败: %v", err)
+	}
+
+	return &Local{dir: dir}, nil
+}
+
+// path 将对象键转换为文件路径，拒绝越出存储目录的键
+func (l *Local) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("无效的对象键: %s", key)
+	}
+
+	return filepath.Join(l.dir, cleaned), nil
+}
+
+// Put 写入对象，先写入临时文件再重命名，避免读到不完整的文件
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	filePath, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := io.Copy(tempFile, r); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+
+	if err := os.Rename(tempFile.Name(), filePath); err != nil {
+		return fmt.Errorf("保存文件失败: %v", err)
+	}
+
+	return nil
+}
+
+// Get 读取对象，返回的 *os.File 同时实现了 io.ReadSeeker
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	filePath, err := l.path(key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ObjectInfo{}, ErrNotExist
+		}
+		return nil, ObjectInfo{}, fmt.Errorf("打开文件失败: %v", err)
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil || fileInfo.IsDir() {
+		file.Close()
+		return nil, ObjectInfo{}, ErrNotExist
+	}
+
+	return file, l.objectInfo(key, fileInfo), nil
+}
+
+// Delete 删除对象
+func (l *Local) Delete(ctx context.Context, key string) error {
+	filePath, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除文件失败: %v", err)
+	}
+
+	return nil
+}
+
+// Stat 获取对象元信息
+func (l *Local) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	filePath, err := l.path(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil || fileInfo.IsDir() {
+		if err == nil || os.IsNotExist(err) {
+			return ObjectInfo{}, ErrNotExist
+		}
+		return ObjectInfo{}, fmt.Errorf("获取文件信息失败: %v", err)
+	}
+
+	return l.objectInfo(key, fileInfo), nil
+}
+
+// List 列出键以 prefix 开头的所有对象，忽略写入中的临时文件
+func (l *Local) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	objects := make([]ObjectInfo, 0)
+	err := filepath.WalkDir(l.dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.dir, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		objects = append(objects, l.objectInfo(key, fileInfo))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("列出文件失败: %v", err)
+	}
+
+	return objects, nil
+}
+
+// SignedURL 本地存储没有独立的访问地址，文件统一通过应用的处理函数读取
+func (l *Local) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return "", ErrSignedURLNotSupported
+}
+
+// objectInfo 根据文件信息构造对象元信息
+func (l *Local) objectInfo(key string, fileInfo os.FileInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:         key,
+		Size:        fileInfo.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+		ModTime:     fileInfo.ModTime(),
+		ETag:        fmt.Sprintf(`"%x-%x"`, fileInfo.ModTime().UnixNano(), fileInfo.Size()),
+	}
+}
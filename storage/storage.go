@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// 存储相关错误
+var (
+	// ErrNotExist 对象不存在
+	ErrNotExist = errors.New("对象不存在")
+	// ErrSignedURLNotSupported 存储后端不支持生成签名URL
+	ErrSignedURLNotSupported = errors.New("存储后端不支持签名URL")
+)
+
+// ObjectInfo 存储对象的元信息
+type ObjectInfo struct {
+	Key         string    // 对象键
+	Size        int64     // 大小（字节）
+	ContentType string    // MIME类型
+	ModTime     time.Time // 最后修改时间
+	ETag        string    // 实体标签
+}
+
+// Storage 上传文件的存储后端
+type Storage interface {
+	// Put 写入对象，size 为 -1 时表示大小未知
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取对象，调用方负责关闭返回的 ReadCloser
+	Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// Stat 获取对象元信息，对象不存在时返回 ErrNotExist
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// List 列出键以 prefix 开头的所有对象
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// SignedURL 生成有时效的直接访问地址，不支持时返回 ErrSignedURLNotSupported
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
@@ -0,0 +1,255 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 统一diff格式默认的上下文行数
+const DiffContextLines = 3
+
+// MaxDiffLines 比较差异时每个版本最多的行数，比较耗时与行数和差异行数的乘积成正比
+const MaxDiffLines = 10000
+
+// diffOp 表示一行的编辑操作：' ' 不变，'-' 删除，'+' 新增
+type diffOp struct {
+	kind byte
+	line string
+	// 该操作之前两边已处理的行数，用于计算行号
+	aIndex int
+	bIndex int
+}
+
+// splitLines 按行拆分文本，忽略末尾换行产生的空行
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// LineCount 返回文本的行数，末尾的换行不计为新的一行
+func LineCount(s string) int {
+	if s == "" {
+		return 0
+	}
+	return strings.Count(strings.TrimSuffix(s, "\n"), "\n") + 1
+}
+
+// diffLines 使用线性空间的 Myers 算法计算两组行之间的最短编辑序列
+// 每次找出编辑路径的中间点，再分别比较两侧，内存占用与行数成正比
+func diffLines(a, b []string) []diffOp {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+
+	size := len(a) + len(b) + 4
+	d := &differ{a: a, b: b, vf: make([]int, size), vb: make([]int, size)}
+	d.ai, d.bi = internLines(a, b)
+	d.compare(0, len(a), 0, len(b))
+
+	// 计算行号
+	aIndex, bIndex := 0, 0
+	for i := range d.ops {
+		d.ops[i].aIndex, d.ops[i].bIndex = aIndex, bIndex
+		if d.ops[i].kind != '+' {
+			aIndex++
+		}
+		if d.ops[i].kind != '-' {
+			bIndex++
+		}
+	}
+
+	return d.ops
+}
+
+// differ 保存一次比较的状态，前向和后向搜索的数组在递归中复用
+type differ struct {
+	a, b   []string
+	ai, bi []int // 每行对应的编号，相同内容的行编号相同，比较编号比比较字符串快
+	vf, vb []int
+	ops    []diffOp
+}
+
+// internLines 为两组行中每种不同的内容分配一个编号
+func internLines(a, b []string) ([]int, []int) {
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		result := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			result[i] = id
+		}
+		return result
+	}
+	return intern(a), intern(b)
+}
+
+// compare 按顺序输出 a[aLo:aHi] 与 b[bLo:bHi] 之间的编辑操作
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	// 公共前缀
+	for aLo < aHi && bLo < bHi && d.ai[aLo] == d.bi[bLo] {
+		d.ops = append(d.ops, diffOp{kind: ' ', line: d.a[aLo]})
+		aLo++
+		bLo++
+	}
+
+	// 公共后缀，在中间部分之后输出
+	aEnd, bEnd := aHi, bHi
+	for aLo < aEnd && bLo < bEnd && d.ai[aEnd-1] == d.bi[bEnd-1] {
+		aEnd--
+		bEnd--
+	}
+
+	switch {
+	case aLo == aEnd:
+		for _, line := range d.b[bLo:bEnd] {
+			d.ops = append(d.ops, diffOp{kind: '+', line: line})
+		}
+	case bLo == bEnd:
+		for _, line := range d.a[aLo:aEnd] {
+			d.ops = append(d.ops, diffOp{kind: '-', line: line})
+		}
+	default:
+		// 去掉公共前后缀后两边都不为空时编辑距离至少为2，中间点两侧的编辑距离都更小
+		x, y := d.middle(aLo, aEnd, bLo, bEnd)
+		d.compare(aLo, x, bLo, y)
+		d.compare(x, aEnd, y, bEnd)
+	}
+
+	for _, line := range d.a[aEnd:aHi] {
+		d.ops = append(d.ops, diffOp{kind: ' ', line: line})
+	}
+}
+
+// middle 同时从两端搜索，返回 a[aLo:aHi] 与 b[bLo:bHi] 的一条最短编辑路径经过的中间点
+// 前向搜索记录每条对角线 k = x - y 上到达的最远 x，后向搜索在反转的序列上进行同样的搜索
+func (d *differ) middle(aLo, aHi, bLo, bHi int) (int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	max := (n + m + 1) / 2
+	offset := max + 1
+	vf, vb := d.vf, d.vb
+	vf[offset+1], vb[offset+1] = 0, 0
+
+	for step := 0; step <= max; step++ {
+		// 前向搜索
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && vf[k-1+offset] < vf[k+1+offset]) {
+				x = vf[k+1+offset]
+			} else {
+				x = vf[k-1+offset] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.ai[aLo+x] == d.bi[bLo+y] {
+				x++
+				y++
+			}
+			vf[k+offset] = x
+
+			// 编辑距离为奇数时在前向搜索中与上一步的后向搜索相遇
+			if c := delta - k; odd && c >= -(step-1) && c <= step-1 && x+vb[c+offset] >= n {
+				return aLo + x, bLo + y
+			}
+		}
+
+		// 后向搜索，坐标从末尾开始计算
+		for c := -step; c <= step; c += 2 {
+			var x int
+			if c == -step || (c != step && vb[c-1+offset] < vb[c+1+offset]) {
+				x = vb[c+1+offset]
+			} else {
+				x = vb[c-1+offset] + 1
+			}
+			y := x - c
+			for x < n && y < m && d.ai[aHi-1-x] == d.bi[bHi-1-y] {
+				x++
+				y++
+			}
+			vb[c+offset] = x
+
+			// 编辑距离为偶数时在后向搜索中与同一步的前向搜索相遇
+			if k := delta - c; !odd && k >= -step && k <= step && vf[k+offset]+x >= n {
+				return aHi - x, bHi - y
+			}
+		}
+	}
+
+	// 不会到达：两个方向的搜索在 max 步之内必然相遇
+	return aHi, bHi
+}
+
+// hunkRange 生成统一diff中 "起始行,行数" 的描述
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// UnifiedDiff 生成两段文本之间的统一格式（unified）行级diff，文本相同时返回空字符串
+func UnifiedDiff(a, b string, fromName, toName string, contextLines int) string {
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	// 找出所有发生变化的位置
+	var changes []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	// 将相距不超过两倍上下文的变化合并为一个区块
+	for i := 0; i < len(changes); {
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*contextLines {
+			j++
+		}
+
+		start := changes[i] - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := changes[j] + contextLines + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		aCount, bCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(ops[start].aIndex, aCount), hunkRange(ops[start].bIndex, bCount))
+		for _, op := range ops[start:end] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			sb.WriteByte('\n')
+		}
+
+		i = j + 1
+	}
+
+	return sb.String()
+}
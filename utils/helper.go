@@ -1,9 +1,20 @@
 package utils
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // 常量定义
 const (
 	// 上传目录
 	UploadsDir = "uploads"
+	// 文件类型内容允许上传的最大大小（100MB）
+	MaxUploadFileSize = 100 << 20
+	// 导入归档允许上传的最大大小（1GB）
+	MaxImportArchiveSize = 1 << 30
 )
 
 // FilterEmpty 过滤空字符串
@@ -16,3 +27,36 @@ func FilterEmpty(strs []string) []string {
 	}
 	return result
 }
+
+// ParseDuration 解析时长字符串，在 time.ParseDuration 的基础上支持以 d 结尾的天数，如 "7d"
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(s, "d"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("无效的时长: %s", s)
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("无效的时长: %s", s)
+	}
+	return d, nil
+}
+
+// FormatFileSize 将字节数格式化为便于阅读的大小，如 "1.5 MB"
+func FormatFileSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
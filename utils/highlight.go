@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// 代码高亮相关常量
+const (
+	// 未识别语言时使用的纯文本语言
+	PlainTextLanguage = "plaintext"
+	// 超过该大小的代码不再做语法高亮，避免大文件拖慢页面渲染
+	MaxHighlightSize = 512 << 10
+	// 高亮使用的配色方案
+	highlightStyle = "github"
+	// 行号锚点前缀，生成 #L10 形式的锚点
+	lineAnchorPrefix = "L"
+)
+
+// languageRule 根据代码特征识别语言的规则
+type languageRule struct {
+	language string
+	pattern  *regexp.Regexp
+}
+
+// languageRules 按顺序匹配的语言识别规则，越靠前的规则特征越明确
+var languageRules = []languageRule{
+	{"bash", regexp.MustCompile(`\A#!.*\b(ba|z|k)?sh\b`)},
+	{"python", regexp.MustCompile(`\A#!.*\bpython`)},
+	{"javascript", regexp.MustCompile(`\A#!.*\bnode\b`)},
+	{"ruby", regexp.MustCompile(`\A#!.*\bruby\b`)},
+	{"php", regexp.MustCompile(`<\?php`)},
+	{"xml", regexp.MustCompile(`\A\s*<\?xml`)},
+	{"html", regexp.MustCompile(`(?i)\A\s*<(!doctype html|html)`)},
+	{"diff", regexp.MustCompile(`(?m)^(diff --git |--- \S.*\n\+\+\+ \S)`)},
+	{"go", regexp.MustCompile(`(?m)^package \w+\s*$[\s\S]*^(func|import|type|var|const)\b`)},
+	{"rust", regexp.MustCompile(`(?m)^\s*(pub\s+)?fn \w+[<(][\s\S]*\blet (mut )?\w`)},
+	{"cpp", regexp.MustCompile(`(?m)^#include\s*[<"][\s\S]*(std::|\bnamespace\b|\btemplate\s*<|\bcout\b)`)},
+	{"c", regexp.MustCompile(`(?m)^#include\s*[<"]`)},
+	{"csharp", regexp.MustCompile(`(?m)^using System(\.\w+)*;`)},
+	{"java", regexp.MustCompile(`(?m)^(import java\.|\s*public (final )?(class|interface) \w+)|System\.out\.print`)},
+	{"python", regexp.MustCompile(`(?m)^(\s*(def|class) \w+.*:\s*$|from [\w.]+ import |import \w+(\.\w+)*\s*$)`)},
+	{"docker", regexp.MustCompile(`(?m)^FROM \S+[\s\S]*^(RUN|CMD|COPY|ENTRYPOINT) `)},
+	{"sql", regexp.MustCompile(`(?im)^\s*(SELECT\s[\s\S]+\sFROM\s|INSERT\s+INTO\s|UPDATE\s+\w+\s+SET\s|DELETE\s+FROM\s|CREATE\s+(TABLE|INDEX|VIEW)\s)`)},
+	{"typescript", regexp.MustCompile(`(?m)(^\s*(export\s+)?interface \w+\s*\{|:\s*(string|number|boolean)(\[\])?\s*[;,)=])`)},
+	{"javascript", regexp.MustCompile(`(?m)(^\s*(const|let|var) \w+\s*=|\bfunction\s*\w*\s*\(|console\.log\(|\brequire\(['"]|^import .* from ['"])`)},
+	{"ruby", regexp.MustCompile(`(?m)^\s*(require ['"]|puts |def \w+[^:]*$)`)},
+	{"css", regexp.MustCompile(`(?m)^\s*[.#]?[\w-]+(\s*[\w.#:>-]+)*\s*\{\s*$\s*[\w-]+\s*:`)},
+	{"bash", regexp.MustCompile(`(?m)^\s*(\$ )?(sudo|echo|export|apt(-get)?|yum|curl|cd|ls|grep|chmod|docker|kubectl|git) `)},
+	{"yaml", regexp.MustCompile(`(?m)\A(---\s*\n)?([\w-]+:( .*)?\n)+[\w-]+:`)},
+}
+
+// NormalizeLanguage 将语言名称或别名（如 "golang"、"js"）转换为统一的小写名称，不支持的语言返回 false
+func NormalizeLanguage(language string) (string, bool) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return "", false
+	}
+
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		return "", false
+	}
+
+	return strings.ToLower(lexer.Config().Name), true
+}
+
+// DetectLanguage 根据文件名和代码内容推断语言，无法识别时返回纯文本
+func DetectLanguage(code string, filename string) string {
+	// 标题像文件名时优先按扩展名识别，如 "main.go"
+	if filename != "" && !strings.ContainsAny(filename, " \t") {
+		if lexer := lexers.Match(filename); lexer != nil {
+			return strings.ToLower(lexer.Config().Name)
+		}
+	}
+
+	trimmed := strings.TrimSpace(code)
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return "json"
+	}
+
+	for _, rule := range languageRules {
+		if rule.pattern.MatchString(code) {
+			if language, ok := NormalizeLanguage(rule.language); ok {
+				return language
+			}
+		}
+	}
+
+	return PlainTextLanguage
+}
+
+// LanguageExtension 返回语言常用的文件扩展名（如 ".go"），未知语言返回 ".txt"
+func LanguageExtension(language string) string {
+	if lexer := lexers.Get(language); lexer != nil {
+		for _, pattern := range lexer.Config().Filenames {
+			// 只使用简单的 *.ext 形式，跳过 Makefile、*.[ch] 等模式
+			if ext := strings.TrimPrefix(pattern, "*"); ext != pattern && !strings.ContainsAny(ext, "*?[") {
+				return ext
+			}
+		}
+	}
+	return ".txt"
+}
+
+// highlightFormatter 生成带可链接行号的HTML，样式通过CSS类名控制
+var highlightFormatter = html.New(
+	html.WithClasses(true),
+	html.WithLineNumbers(true),
+	html.LineNumbersInTable(true),
+	html.WithLinkableLineNumbers(true, lineAnchorPrefix),
+	html.TabWidth(4),
+)
+
+// HighlightCode 对代码进行语法高亮，返回带行号的HTML，行号锚点形如 #L10
+func HighlightCode(code string, language string) (template.HTML, error) {
+	lexer := lexers.Get(language)
+	if lexer == nil || len(code) > MaxHighlightSize {
+		lexer = lexers.Get(PlainTextLanguage)
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, code)
+	if err != nil {
+		return "", fmt.Errorf("代码解析失败: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := highlightFormatter.Format(&buf, styles.Get(highlightStyle), iterator); err != nil {
+		return "", fmt.Errorf("代码高亮失败: %v", err)
+	}
+
+	return template.HTML(buf.String()), nil
+}
+
+var (
+	highlightCSS     template.CSS
+	highlightCSSOnce sync.Once
+)
+
+// HighlightCSS 返回代码高亮所需的样式表
+func HighlightCSS() template.CSS {
+	highlightCSSOnce.Do(func() {
+		var buf bytes.Buffer
+		if err := highlightFormatter.WriteCSS(&buf, styles.Get(highlightStyle)); err == nil {
+			highlightCSS = template.CSS(buf.String())
+		}
+	})
+	return highlightCSS
+}